@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// batchResult is the outcome of applying one patch file
+type batchResult struct {
+	release string
+	status  string
+	err     error
+}
+
+// runBatch applies every patch file of the batch directory to the latest revision of the release it is named after
+func (o *ModifySecretOptions) runBatch() error {
+	entries, err := os.ReadDir(o.batchDir)
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := o.namespaces()
+	if err != nil {
+		return err
+	}
+
+	results := []batchResult{}
+	failed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		fileResults := o.applyPatchFileInNamespaces(namespaces, name, filepath.Join(o.batchDir, entry.Name()))
+		results = append(results, fileResults...)
+
+		stop := false
+		for _, result := range fileResults {
+			if result.err != nil {
+				failed++
+				stop = !o.continueOnError
+			}
+		}
+		if stop {
+			break
+		}
+	}
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RELEASE\tRESULT")
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(w, "%s\tfailed: %v\n", result.release, result.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", result.release, result.status)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d patches failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// applyPatchFileInNamespaces applies a patch file to the release in each namespace.
+// With --namespace-selector, namespaces without the release or which can't be read are skipped.
+func (o *ModifySecretOptions) applyPatchFileInNamespaces(namespaces []string, name, path string) []batchResult {
+	if o.namespaceSelector == "" {
+		status, err := o.applyPatchFile(o.namespace, name, path)
+		return []batchResult{{release: name, status: status, err: err}}
+	}
+
+	results := []batchResult{}
+	for _, namespace := range namespaces {
+		status, err := o.applyPatchFile(namespace, name, path)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if apierrors.IsForbidden(err) {
+			logrus.Warnf("skipping namespace %q: %v", namespace, err)
+			continue
+		}
+		results = append(results, batchResult{release: namespace + "/" + name, status: status, err: err})
+	}
+
+	if len(results) == 0 {
+		err := apierrors.NewNotFound(schema.GroupResource{Group: "helm.sh", Resource: "releases"}, name)
+		return []batchResult{{release: name, err: err}}
+	}
+
+	return results
+}
+
+// applyPatchFile applies a merge patch, written in YAML or JSON, to the latest revision of a release
+func (o *ModifySecretOptions) applyPatchFile(namespace, name, path string) (string, error) {
+	patch, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	patch, err = yaml.YAMLToJSON(patch)
+	if err != nil {
+		return "", fmt.Errorf("invalid patch %s: %v", path, err)
+	}
+
+	secret, err := secrets.Latest(context.TODO(), o.driver, name, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	err = secrets.CheckMutable(secret)
+	if err != nil {
+		return "", err
+	}
+
+	original, key, err := o.releaseOf(secret)
+	if err != nil {
+		return "", err
+	}
+
+	patched, err := jsonpatch.MergePatch(original, patch)
+	if err != nil {
+		return "", err
+	}
+
+	if jsonpatch.Equal(original, patched) {
+		return "unchanged", nil
+	}
+
+	if o.dryRun {
+		return fmt.Sprintf("%s patched (dry run)", secret.Name), nil
+	}
+
+	encoded, err := o.encode(patched)
+	if err != nil {
+		return "", err
+	}
+	secret.Data[key] = encoded
+	o.applyMetadata(secret)
+
+	_, err = o.driver.Update(context.TODO(), secret, o.fieldManager)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s patched", secret.Name), nil
+}
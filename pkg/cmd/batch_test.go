@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunBatch(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "myapp.yaml"), []byte("config:\n  image: registry.example.com/app\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "missing.json"), []byte(`{"config":{"image":"x"}}`), 0644))
+
+	testcases := []struct {
+		name            string
+		dryRun          bool
+		continueOnError bool
+		expected        string
+		output          []string
+	}{
+		{
+			name:            "stops at first failure",
+			continueOnError: false,
+			expected:        `{"config":{"image":"docker.io/app"},"version":2}`,
+			output:          []string{"missing", "not found"},
+		}, {
+			name:            "continues after failure",
+			continueOnError: true,
+			expected:        `{"config":{"image":"registry.example.com/app"},"version":2}`,
+			output:          []string{"missing", "not found", "sh.helm.release.v1.myapp.v2 patched"},
+		}, {
+			name:            "dry run",
+			dryRun:          true,
+			continueOnError: true,
+			expected:        `{"config":{"image":"docker.io/app"},"version":2}`,
+			output:          []string{"sh.helm.release.v1.myapp.v2 patched (dry run)"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(
+				releaseSecret(t, namespace, "myapp", 1, `{"config":{"image":"docker.io/old"},"version":1}`),
+				releaseSecret(t, namespace, "myapp", 2, `{"config":{"image":"docker.io/app"},"version":2}`),
+			)
+
+			out := &bytes.Buffer{}
+			modify := ModifySecretOptions{
+				IOStreams:       genericclioptions.IOStreams{Out: out},
+				kubeclient:      client,
+				namespace:       namespace,
+				batchDir:        dir,
+				dryRun:          tc.dryRun,
+				continueOnError: tc.continueOnError,
+			}
+			require.Error(t, modify.Run())
+
+			for _, expected := range tc.output {
+				assert.Contains(t, out.String(), expected)
+			}
+
+			secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), "sh.helm.release.v1.myapp.v2", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, decodeRelease(t, secret.Data["release"]))
+		})
+	}
+}
+
+func TestRunBatchNamespaceSelector(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.yaml"), []byte("config:\n  image: registry.example.com/api\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "web.yaml"), []byte("config:\n  image: registry.example.com/web\n"), 0644))
+
+	client := fake.NewSimpleClientset(
+		namespace("payments-eu", map[string]string{"team": "payments"}),
+		namespace("payments-us", map[string]string{"team": "payments"}),
+		namespace("search", map[string]string{"team": "search"}),
+		releaseSecret(t, "payments-eu", "api", 1, `{"config":{"image":"docker.io/api"}}`),
+		releaseSecret(t, "payments-us", "api", 1, `{"config":{"image":"docker.io/api"}}`),
+		releaseSecret(t, "search", "api", 1, `{"config":{"image":"docker.io/api"}}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:         genericclioptions.IOStreams{Out: out},
+		kubeclient:        client,
+		batchDir:          dir,
+		continueOnError:   true,
+		namespaceSelector: "team=payments",
+	}
+	assert.EqualError(t, modify.Run(), "1 of 3 patches failed")
+	assert.Contains(t, out.String(), "payments-eu/api  sh.helm.release.v1.api.v1 patched")
+	assert.Contains(t, out.String(), "payments-us/api  sh.helm.release.v1.api.v1 patched")
+	assert.Contains(t, out.String(), `web              failed: releases.helm.sh "web" not found`)
+
+	for ns, expected := range map[string]string{
+		"payments-eu": `{"config":{"image":"registry.example.com/api"}}`,
+		"payments-us": `{"config":{"image":"registry.example.com/api"}}`,
+		"search":      `{"config":{"image":"docker.io/api"}}`,
+	} {
+		secret, err := client.CoreV1().Secrets(ns).Get(context.TODO(), "sh.helm.release.v1.api.v1", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.JSONEq(t, expected, decodeRelease(t, secret.Data["release"]))
+	}
+}
+
+// releaseSecret builds a secret holding a revision of a Helm release
+func releaseSecret(t *testing.T, namespace, name string, version int, release string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, version),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"owner":   "helm",
+				"name":    name,
+				"version": strconv.Itoa(version),
+			},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": encodeRelease(t, release)},
+	}
+}
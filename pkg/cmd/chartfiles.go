@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// runChartFiles prints the files packaged in the chart of the release
+func (o *ModifySecretOptions) runChartFiles() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	content, _, err := o.releaseOf(secret)
+	if err != nil {
+		return err
+	}
+
+	files, err := release.ChartFiles(content)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE")
+	for _, file := range files {
+		fmt.Fprintf(w, "%s\t%d\n", file.Name, len(file.Data))
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunChartFiles(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"chart":{"files":[{"name":"config/app.conf","data":"bGlzdGVuIDgwCg=="},{"name":"README.md","data":"IyBhcHAK"}]}}`)},
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		chartFiles: true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, "NAME             SIZE\nconfig/app.conf  10\nREADME.md        6\n", out.String())
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+)
+
+// runSetChartMetadata corrects the chart version, app version and sources recorded in the release, without opening an editor
+func (o *ModifySecretOptions) runSetChartMetadata() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	err = secrets.CheckMutable(secret)
+	if err != nil {
+		return err
+	}
+
+	warnIfManaged(secret)
+
+	content, key, err := o.releaseOf(secret)
+	if err != nil {
+		return err
+	}
+
+	rel, err := release.Unmarshal(content)
+	if err != nil {
+		return release.WithKey(err, key)
+	}
+
+	if o.chartVersion != "" {
+		logrus.Infof("chart version: %q -> %q", rel.Chart.Metadata.Version, o.chartVersion)
+	}
+	if o.appVersion != "" {
+		logrus.Infof("app version: %q -> %q", rel.Chart.Metadata.AppVersion, o.appVersion)
+	}
+
+	edited, err := release.SetChartVersions(content, o.chartVersion, o.appVersion)
+	if err != nil {
+		return err
+	}
+
+	if len(o.chartSources) > 0 {
+		sources, err := release.ChartSources(content)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("chart sources: %q -> %q", sources, o.chartSources)
+
+		edited, err = release.SetChartSources(edited, o.chartSources)
+		if err != nil {
+			return err
+		}
+	}
+
+	secret.Data[key], err = o.encode(edited)
+	if err != nil {
+		return err
+	}
+	o.applyMetadata(secret)
+
+	if o.dryRun {
+		logrus.Infof("chart metadata of release %q set (dry run)", o.secretName)
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.Update(context.TODO(), secret, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("chart metadata of release %q set", o.secretName)
+	return nil
+}
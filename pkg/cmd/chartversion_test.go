@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunSetChartMetadata(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.0","appVersion":"2.0","sources":["oci://old.example.com/charts/app"]}}}`)},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient:   client,
+		secretName:   "mysecret",
+		namespace:    "mynamespace",
+		chartVersion: "1.0.1",
+		appVersion:   "2.1",
+		chartSources: []string{"oci://new.example.com/charts/app"},
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.1","appVersion":"2.1","sources":["oci://new.example.com/charts/app"]}}}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestValidateNotEmpty(t *testing.T) {
+	cmd := NewCmdModifySecret(genericclioptions.IOStreams{})
+	require.NoError(t, cmd.Flags().Parse([]string{"--set-app-version", "2.1"}))
+	assert.NoError(t, validateNotEmpty(cmd, "set-chart-version", "set-app-version"))
+
+	require.NoError(t, cmd.Flags().Parse([]string{"--set-chart-version", " "}))
+	assert.EqualError(t, validateNotEmpty(cmd, "set-chart-version", "set-app-version"), "--set-chart-version must not be empty")
+}
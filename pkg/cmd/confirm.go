@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// confirmName asks the user to type the name of the secret before it is modified,
+// when requested on the command line or configured for the namespace
+func (o *ModifySecretOptions) confirmName() error {
+	if o.dryRun {
+		return nil
+	}
+
+	if !o.requiresConfirmName() {
+		return nil
+	}
+
+	answer, err := o.prompt(fmt.Sprintf("type the name of the secret %q in namespace %q to confirm: ", o.secretName, o.namespace))
+	if err != nil {
+		return fmt.Errorf("confirmation aborted: %v", err)
+	}
+
+	if answer != o.secretName {
+		return fmt.Errorf("confirmation failed, secret %q left untouched", o.secretName)
+	}
+
+	return nil
+}
+
+// requiresConfirmName tells whether the name of the secret must be typed to confirm the edit,
+// on the command line or in the configuration of the namespace
+func (o *ModifySecretOptions) requiresConfirmName() bool {
+	return o.requireConfirmName || o.config != nil && o.config.RequiresConfirmName(o.namespace)
+}
+
+// confirmClearedKeys lists the keys of the secret the values would clear, with their current size,
+// and asks the user to type yes before clearing them, unless --yes is set. Clearing a key may break
+// the workloads reading it, so this is asked on top of confirmName.
+func (o *ModifySecretOptions) confirmClearedKeys(secret *v1.Secret, values map[string][]byte) error {
+	cleared := []string{}
+	for k, v := range values {
+		if len(v) == 0 && len(secret.Data[k]) > 0 {
+			cleared = append(cleared, k)
+		}
+	}
+	if len(cleared) == 0 {
+		return nil
+	}
+	sort.Strings(cleared)
+
+	fmt.Fprintf(o.IOStreams.ErrOut, "the following keys of secret %q will be cleared:\n", o.secretName)
+	for _, k := range cleared {
+		fmt.Fprintf(o.IOStreams.ErrOut, "  %s (%d bytes)\n", k, len(secret.Data[k]))
+	}
+
+	if o.dryRun || o.yes {
+		return nil
+	}
+
+	answer, err := o.prompt("type yes to clear them: ")
+	if err != nil {
+		return fmt.Errorf("confirmation aborted: %v", err)
+	}
+
+	if answer != "yes" {
+		return fmt.Errorf("clearing keys not confirmed, secret %q left untouched", o.secretName)
+	}
+
+	return nil
+}
+
+// prompt asks the user a question on stderr and returns the answer read from stdin. The reader is kept
+// across questions, so piped answers it buffered are left for the next ones.
+func (o *ModifySecretOptions) prompt(question string) (string, error) {
+	fmt.Fprint(o.IOStreams.ErrOut, question)
+	if o.answers == nil {
+		o.answers = bufio.NewReader(o.IOStreams.In)
+	}
+	answer, err := o.answers.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
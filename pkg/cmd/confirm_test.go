@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestConfirmName(t *testing.T) {
+	testcases := []struct {
+		name               string
+		namespace          string
+		requireConfirmName bool
+		dryRun             bool
+		input              string
+		err                string
+	}{
+		{
+			name:      "not required",
+			namespace: "staging",
+		},
+		{
+			name:               "required by flag and confirmed",
+			namespace:          "staging",
+			requireConfirmName: true,
+			input:              "mysecret\n",
+		},
+		{
+			name:      "required by namespace pattern and confirmed",
+			namespace: "prod-eu",
+			input:     "  mysecret  \n",
+		},
+		{
+			name:      "required by namespace pattern and mistyped",
+			namespace: "production",
+			input:     "mysecrte\n",
+			err:       `confirmation failed, secret "mysecret" left untouched`,
+		},
+		{
+			name:      "required and no input",
+			namespace: "production",
+			err:       "confirmation aborted: EOF",
+		},
+		{
+			name:      "not required in dry run",
+			namespace: "production",
+			dryRun:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			modify := ModifySecretOptions{
+				IOStreams:          genericclioptions.IOStreams{In: strings.NewReader(tc.input), ErrOut: &bytes.Buffer{}},
+				secretName:         "mysecret",
+				namespace:          tc.namespace,
+				dryRun:             tc.dryRun,
+				requireConfirmName: tc.requireConfirmName,
+				config:             &config.Config{ConfirmName: []string{"prod*"}},
+			}
+
+			err := modify.confirmName()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
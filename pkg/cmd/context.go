@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"golang.org/x/term"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// pickContextIfNeeded lets the user pick the kubeconfig context to use when none is given on the command line,
+// stdin is a terminal and the kubeconfig holds several contexts
+func (o *ModifySecretOptions) pickContextIfNeeded() error {
+	if !o.pickContext || (o.configFlags.Context != nil && *o.configFlags.Context != "") || !isTerminal(o.IOStreams.In) {
+		return nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if o.configFlags.KubeConfig != nil {
+		loadingRules.ExplicitPath = *o.configFlags.KubeConfig
+	}
+
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return err
+	}
+
+	contexts := make([]string, 0, len(raw.Contexts))
+	for name := range raw.Contexts {
+		contexts = append(contexts, name)
+	}
+	if len(contexts) < 2 {
+		return nil
+	}
+	sort.Strings(contexts)
+
+	picked, err := o.chooseContext(contexts, raw.CurrentContext)
+	if err != nil {
+		return err
+	}
+
+	o.configFlags.Context = &picked
+	return nil
+}
+
+// chooseContext presents a numbered menu of the contexts on stderr and returns the one chosen,
+// the current context when the answer is empty
+func (o *ModifySecretOptions) chooseContext(contexts []string, current string) (string, error) {
+	for i, name := range contexts {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(o.IOStreams.ErrOut, "%s %d) %s\n", marker, i+1, name)
+	}
+
+	answer, err := o.prompt(fmt.Sprintf("context to use [%s]: ", current))
+	if err != nil {
+		return "", fmt.Errorf("no context picked: %v", err)
+	}
+
+	if answer == "" && current != "" {
+		return current, nil
+	}
+
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > len(contexts) {
+		return "", fmt.Errorf("invalid choice %q, expected a number between 1 and %d", answer, len(contexts))
+	}
+
+	return contexts[choice-1], nil
+}
+
+// isTerminal tells whether the reader or writer is an interactive terminal
+func isTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
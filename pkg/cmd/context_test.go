@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestChooseContext(t *testing.T) {
+	contexts := []string{"dev", "prod", "staging"}
+
+	testcases := []struct {
+		name     string
+		answer   string
+		expected string
+		err      string
+	}{
+		{
+			name:     "pick by number",
+			answer:   "3\n",
+			expected: "staging",
+		},
+		{
+			name:     "empty answer keeps the current context",
+			answer:   "\n",
+			expected: "dev",
+		},
+		{
+			name:   "out of range",
+			answer: "4\n",
+			err:    `invalid choice "4", expected a number between 1 and 3`,
+		},
+		{
+			name:   "no answer",
+			answer: "",
+			err:    "no context picked: EOF",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			errOut := &bytes.Buffer{}
+			modify := ModifySecretOptions{
+				IOStreams: genericclioptions.IOStreams{In: strings.NewReader(tc.answer), ErrOut: errOut},
+			}
+
+			picked, err := modify.chooseContext(contexts, "dev")
+			assert.Equal(t, "* 1) dev\n  2) prod\n  3) staging\ncontext to use [dev]: ", errOut.String())
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, picked)
+		})
+	}
+}
+
+func TestPickContextSkippedWhenNotInteractive(t *testing.T) {
+	modify := NewModifySecretOptions(genericclioptions.IOStreams{In: strings.NewReader("2\n")})
+	modify.pickContext = true
+	require.NoError(t, modify.pickContextIfNeeded())
+	assert.Empty(t, *modify.configFlags.Context)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// releaseSecretType is the type of the secrets Helm stores releases in
+const releaseSecretType = "helm.sh/release.v1"
+
+// runConvertStorage copies every revision of the release from --storage to --convert-storage,
+// keeping names, labels and the encoded release, which Helm stores the same way in both.
+// The originals are deleted with --delete-source, once all the revisions are copied.
+func (o *ModifySecretOptions) runConvertStorage() error {
+	items, err := o.driver.List(context.TODO(), o.namespace, fmt.Sprintf("%s,name=%s", releaseSelector, o.secretName), o.fieldSelector)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return apierrors.NewNotFound(schema.GroupResource{Group: "helm.sh", Resource: "releases"}, o.secretName)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		vi, _ := strconv.Atoi(items[i].Labels["version"])
+		vj, _ := strconv.Atoi(items[j].Labels["version"])
+		return vi < vj
+	})
+
+	opts := o.modifyOptions()
+	keys := make([]string, len(items))
+	for i := range items {
+		keys[i], err = opts.ReleaseKeyOf(&items[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.dryRun {
+		for _, secret := range items {
+			logrus.Infof("revision %q would be copied to a %s (dry run)", secret.Name, o.convertStorage)
+		}
+		return nil
+	}
+
+	target, err := secrets.NewDriver(o.convertStorage, o.kubeclient)
+	if err != nil {
+		return err
+	}
+
+	for i, secret := range items {
+		_, err = target.Create(context.TODO(), convertedRevision(&secret, keys[i], o.convertStorage), o.fieldManager)
+		if err != nil {
+			return fmt.Errorf("copying revision %q to a %s: %w", secret.Name, o.convertStorage, err)
+		}
+		logrus.Infof("revision %q copied to a %s", secret.Name, o.convertStorage)
+	}
+
+	if !o.deleteSource {
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range items {
+		err = o.driver.Delete(context.TODO(), secret.Name, o.namespace)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("%s %q deleted", o.storage, secret.Name)
+	}
+
+	return nil
+}
+
+// convertedRevision returns the copy of the revision to create in the target storage, with the release
+// under the same key
+func convertedRevision(secret *v1.Secret, key, storage string) *v1.Secret {
+	converted := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secret.Name,
+			Namespace:   secret.Namespace,
+			Labels:      secret.Labels,
+			Annotations: secret.Annotations,
+		},
+		Data: map[string][]byte{key: secret.Data[key]},
+	}
+	if storage == secrets.StorageSecret {
+		converted.Type = releaseSecretType
+	}
+
+	return converted
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunConvertStorage(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	objects := []runtime.Object{
+		releaseSecret(t, namespace, "other", 1, `{"name":"other","version":1,"info":{"status":"deployed"}}`),
+	}
+	for version := 1; version <= 2; version++ {
+		objects = append(objects, releaseSecret(t, namespace, "myapp", version, fmt.Sprintf(`{"name":"myapp","version":%d,"info":{"status":"deployed"}}`, version)))
+	}
+	client := fake.NewSimpleClientset(objects...)
+
+	modify := ModifySecretOptions{
+		kubeclient:     client,
+		secretName:     "myapp",
+		namespace:      namespace,
+		convertStorage: secrets.StorageConfigMap,
+		deleteSource:   true,
+		dryRun:         true,
+	}
+	require.NoError(t, modify.Run())
+	configMaps, err := client.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configMaps.Items)
+
+	modify.dryRun = false
+	require.NoError(t, modify.Run())
+	assert.ElementsMatch(t, []string{"sh.helm.release.v1.other.v1"}, secretNames(t, client, namespace))
+
+	driver := &secrets.ConfigMapDriver{Client: client}
+	revisions, err := driver.List(context.TODO(), namespace, "owner=helm,name=myapp", "")
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	for _, revision := range revisions {
+		rel, err := release.Parse(revision.Data["release"])
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("sh.helm.release.v1.myapp.v%d", rel.Version), revision.Name)
+		assert.Equal(t, fmt.Sprint(rel.Version), revision.Labels["version"])
+	}
+
+	modify = ModifySecretOptions{
+		kubeclient:     client,
+		secretName:     "myapp",
+		namespace:      namespace,
+		storage:        secrets.StorageConfigMap,
+		convertStorage: secrets.StorageSecret,
+	}
+	require.NoError(t, modify.Run())
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), "sh.helm.release.v1.myapp.v2", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, "helm.sh/release.v1", secret.Type)
+	configMap, err := driver.Get(context.TODO(), "sh.helm.release.v1.myapp.v2", namespace)
+	require.NoError(t, err)
+	assert.Equal(t, configMap.Data["release"], secret.Data["release"])
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"sigs.k8s.io/yaml"
+)
+
+// runDiffDefaults prints the YAML diff between the default values of the chart and the values
+// the release is deployed with, to show what was customized
+func (o *ModifySecretOptions) runDiffDefaults() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	content, _, err := o.releaseOf(secret)
+	if err != nil {
+		return err
+	}
+
+	defaults, err := release.DefaultValues(content)
+	if err != nil {
+		return err
+	}
+
+	effective, err := release.EffectiveValues(content)
+	if err != nil {
+		return err
+	}
+
+	defaultsYAML, err := yaml.JSONToYAML(defaults)
+	if err != nil {
+		return err
+	}
+
+	effectiveYAML, err := yaml.JSONToYAML(effective)
+	if err != nil {
+		return err
+	}
+
+	return o.printUnifiedDiff(defaultsYAML, effectiveYAML, fmt.Sprintf("%s (chart defaults)", o.secretName), fmt.Sprintf("%s (deployed values)", o.secretName))
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunDiffDefaults(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"payload": encodeRelease(t, `{"chart":{"values":{"image":{"tag":"latest"},"replicas":1}},"config":{"image":{"tag":"v2"}}}`)},
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:    genericclioptions.IOStreams{Out: out},
+		kubeclient:   client,
+		secretName:   "mysecret",
+		namespace:    "mynamespace",
+		releaseKey:   "release",
+		diffDefaults: true,
+		diffContext:  3,
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, `--- mysecret (chart defaults)
++++ mysecret (deployed values)
+@@ -1,3 +1,3 @@
+ image:
+-  tag: latest
++  tag: v2
+ replicas: 1
+`, out.String())
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/modify"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	v1 "k8s.io/api/core/v1"
+)
+
+// helmLabels are the labels Helm sets on the secret of every revision
+var helmLabels = []string{"name", "owner", "status", "version"}
+
+// diagnosis is the report of --diagnose on the secret of a release
+type diagnosis struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Type      string            `json:"type"`
+	Immutable bool              `json:"immutable"`
+	Labels    map[string]string `json:"labels"`
+	Keys      []keyDiagnosis    `json:"keys"`
+	// Release describes the release decoded from the release key, or why it couldn't be
+	Release   string   `json:"release"`
+	Anomalies []string `json:"anomalies"`
+}
+
+// keyDiagnosis describes the encoding of a key of the secret
+type keyDiagnosis struct {
+	Key         string `json:"key"`
+	Size        int    `json:"size"`
+	Layers      string `json:"layers"`
+	Base64Depth int    `json:"base64_depth"`
+	Gzip        bool   `json:"gzip"`
+	DecodedSize int    `json:"decoded_size"`
+	Error       string `json:"error,omitempty"`
+}
+
+// runDiagnose prints a read-only report on the secret of a release: its metadata,
+// the encoding layers of each key, whether the release decodes, and the anomalies found
+func (o *ModifySecretOptions) runDiagnose() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	report := o.diagnoseSecret(secret)
+	if o.output == "json" {
+		return o.writeJSON(report)
+	}
+
+	return o.printDiagnosis(report)
+}
+
+// diagnoseSecret inspects the secret
+func (o *ModifySecretOptions) diagnoseSecret(secret *v1.Secret) diagnosis {
+	report := diagnosis{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Type:      string(secret.Type),
+		Immutable: secret.Immutable != nil && *secret.Immutable,
+		Labels:    secret.Labels,
+		Keys:      []keyDiagnosis{},
+		Anomalies: []string{},
+	}
+
+	if (o.storage == "" || o.storage == secrets.StorageSecret) && report.Type != releaseSecretType {
+		report.Anomalies = append(report.Anomalies, fmt.Sprintf("type is %q, Helm stores releases in secrets of type %s", report.Type, releaseSecretType))
+	}
+	for _, label := range helmLabels {
+		if secret.Labels[label] == "" {
+			report.Anomalies = append(report.Anomalies, fmt.Sprintf("label %q is missing", label))
+		}
+	}
+	if report.Immutable {
+		report.Anomalies = append(report.Anomalies, "the secret is immutable, its data can't be edited in place")
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	opts := o.modifyOptions()
+	for _, k := range keys {
+		entry := keyDiagnosis{Key: k, Size: len(secret.Data[k]), Layers: "-"}
+		if !opts.DecodesKey(k) {
+			entry.Error = "not decoded"
+			report.Keys = append(report.Keys, entry)
+			continue
+		}
+
+		decoded, layers, err := release.Unwrap(secret.Data[k])
+		if err != nil {
+			entry.Error = err.Error()
+		} else {
+			entry.Layers = layers.String()
+			entry.DecodedSize = len(decoded)
+			for _, layer := range layers {
+				switch layer {
+				case release.LayerBase64:
+					entry.Base64Depth++
+				case release.LayerGzip:
+					entry.Gzip = true
+				}
+			}
+		}
+		report.Keys = append(report.Keys, entry)
+	}
+
+	key := o.releaseKey
+	if key == "" {
+		key = modify.DefaultReleaseKey
+	}
+	if opts.DecodesKey(key) {
+		report.Release, report.Anomalies = diagnoseRelease(secret, key, report.Anomalies)
+	} else {
+		report.Release = "not decoded"
+	}
+
+	return report
+}
+
+// diagnoseRelease decodes the release stored in the key of the secret, describes it
+// and adds the anomalies found to the list
+func diagnoseRelease(secret *v1.Secret, key string, anomalies []string) (string, []string) {
+	data, ok := secret.Data[key]
+	if !ok {
+		return "missing", append(anomalies, fmt.Sprintf("no key %q holding the release", key))
+	}
+
+	content, layers, err := release.Unwrap(data)
+	if err != nil {
+		return "undecodable", append(anomalies, release.WithKey(err, key).Error())
+	}
+
+	if !layers.Equal(release.HelmLayers) {
+		anomalies = append(anomalies, fmt.Sprintf("key %q is stored as %s instead of %s, --repair stores it the way Helm does", key, layers, release.HelmLayers))
+	}
+
+	rel, err := release.Unmarshal(content)
+	if err != nil {
+		return "invalid", append(anomalies, fmt.Sprintf("key %q doesn't hold a valid Helm release: %v", key, err))
+	}
+
+	if secret.Labels["name"] != "" && secret.Labels["name"] != rel.Name {
+		anomalies = append(anomalies, fmt.Sprintf("label \"name\" is %q but the release is named %q", secret.Labels["name"], rel.Name))
+	}
+	if expected, err := releaseLabels(rel); err == nil {
+		for _, label := range []string{"status", "version"} {
+			if secret.Labels[label] != "" && secret.Labels[label] != expected[label] {
+				anomalies = append(anomalies, fmt.Sprintf("label %q is %q but the release says %q, --fix-labels reconciles them", label, secret.Labels[label], expected[label]))
+			}
+		}
+	}
+
+	return fmt.Sprintf("valid, %s revision %d (%s)", rel.Name, rel.Version, rel.Info.Status), anomalies
+}
+
+// printDiagnosis prints the report as text
+func (o *ModifySecretOptions) printDiagnosis(report diagnosis) error {
+	labels := make([]string, 0, len(report.Labels))
+	for k, v := range report.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Secret:\t%s\n", report.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", report.Namespace)
+	fmt.Fprintf(w, "Type:\t%s\n", report.Type)
+	fmt.Fprintf(w, "Immutable:\t%t\n", report.Immutable)
+	fmt.Fprintf(w, "Labels:\t%s\n", strings.Join(labels, ", "))
+	fmt.Fprintf(w, "Keys:\t%d\n", len(report.Keys))
+	fmt.Fprintf(w, "Release:\t%s\n", report.Release)
+	w.Flush()
+
+	fmt.Fprintln(o.IOStreams.Out)
+
+	w = tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSIZE\tLAYERS\tBASE64\tGZIP\tDECODED SIZE\tERROR")
+	for _, entry := range report.Keys {
+		gzip := "no"
+		if entry.Gzip {
+			gzip = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\t%d\t%s\n", entry.Key, entry.Size, entry.Layers, entry.Base64Depth, gzip, entry.DecodedSize, entry.Error)
+	}
+	w.Flush()
+
+	fmt.Fprintln(o.IOStreams.Out)
+
+	if len(report.Anomalies) == 0 {
+		_, err := fmt.Fprintln(o.IOStreams.Out, "No anomalies found.")
+		return err
+	}
+
+	fmt.Fprintln(o.IOStreams.Out, "Anomalies:")
+	for _, anomaly := range report.Anomalies {
+		fmt.Fprintf(o.IOStreams.Out, "  - %s\n", anomaly)
+	}
+
+	return nil
+}
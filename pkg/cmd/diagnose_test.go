@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunDiagnose(t *testing.T) {
+	const namespace = "mynamespace"
+
+	healthy := releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"deployed"}}`)
+	healthy.Labels["status"] = "deployed"
+
+	broken := releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","version":2,"info":{"status":"deployed"}}`)
+	broken.Type = "Opaque"
+	broken.Labels["status"] = "superseded"
+	broken.Data["release"] = release.EncodeUncompressed(broken.Data["release"])
+	broken.Data["token"] = []byte("s3cr3t")
+
+	client := fake.NewSimpleClientset(healthy, broken)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		secretName: healthy.Name,
+		namespace:  namespace,
+		diagnose:   true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Contains(t, out.String(), "Release:    valid, myapp revision 1 (deployed)\n")
+	assert.Contains(t, out.String(), "release  112   base64+gzip  1       yes   57            \n")
+	assert.Contains(t, out.String(), "No anomalies found.\n")
+
+	out.Reset()
+	modify.secretName = broken.Name
+	require.NoError(t, modify.Run())
+	assert.Contains(t, out.String(), `Anomalies:
+  - type is "Opaque", Helm stores releases in secrets of type helm.sh/release.v1
+  - key "release" is stored as base64+base64+gzip instead of base64+gzip, --repair stores it the way Helm does
+  - label "status" is "superseded" but the release says "deployed", --fix-labels reconciles them
+`)
+
+	out.Reset()
+	modify.output = "json"
+	require.NoError(t, modify.Run())
+	report := diagnosis{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Keys, 2)
+	assert.Equal(t, keyDiagnosis{Key: "release", Size: len(broken.Data["release"]), Layers: "base64+base64+gzip", Base64Depth: 2, Gzip: true, DecodedSize: 57}, report.Keys[0])
+	assert.Equal(t, keyDiagnosis{Key: "token", Size: 6, Layers: "plain", DecodedSize: 6}, report.Keys[1])
+	assert.Len(t, report.Anomalies, 3)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/diff"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// printDiff prints the unified diff between the release before and after the edit
+func (o *ModifySecretOptions) printDiff(before, after []byte) error {
+	return o.printUnifiedDiff(before, after, fmt.Sprintf("%s (live)", o.secretName), fmt.Sprintf("%s (edited)", o.secretName))
+}
+
+// printUnifiedDiff prints the unified diff between two contents, with the context of --diff-context,
+// colored when printed to a terminal
+func (o *ModifySecretOptions) printUnifiedDiff(before, after []byte, fromFile, toFile string) error {
+	_, err := fmt.Fprint(o.IOStreams.Out, diff.Unified(before, after, diff.Options{
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  o.diffContext,
+		Color:    isTerminal(o.IOStreams.Out) && os.Getenv("NO_COLOR") == "",
+	}))
+	return err
+}
+
+// printEncodedDiff summarizes how the release as stored in the secret changed with the edit
+func (o *ModifySecretOptions) printEncodedDiff(before, after []byte) {
+	layersBefore, layersAfter := release.DetectLayers(before), release.DetectLayers(after)
+
+	structure := "unchanged"
+	if !layersBefore.Equal(layersAfter) {
+		structure = "changed"
+	}
+
+	fmt.Fprintf(o.IOStreams.Out, "encoded release: %d -> %d bytes (%+d), layers %s -> %s (%s)\n",
+		len(before), len(after), len(after)-len(before), layersBefore, layersAfter, structure)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestPrintDiffContext(t *testing.T) {
+	before := []byte("a: 1\nb: 2\nc: 3\nd: 4\ne: 5\nf: 6\ng: 7\n")
+	after := []byte("a: 1\nb: 2\nc: 3\nd: 40\ne: 5\nf: 6\ng: 7\n")
+
+	testcases := []struct {
+		name     string
+		context  int
+		expected string
+	}{
+		{
+			name:     "default context",
+			context:  3,
+			expected: "@@ -1,7 +1,7 @@\n a: 1\n b: 2\n c: 3\n-d: 4\n+d: 40\n e: 5\n f: 6\n g: 7\n",
+		},
+		{
+			name:     "one line of context",
+			context:  1,
+			expected: "@@ -3,3 +3,3 @@\n c: 3\n-d: 4\n+d: 40\n e: 5\n",
+		},
+		{
+			name:     "no context",
+			context:  0,
+			expected: "@@ -4 +4 @@\n-d: 4\n+d: 40\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			modify := ModifySecretOptions{
+				IOStreams:   genericclioptions.IOStreams{Out: out},
+				secretName:  "mysecret",
+				diffContext: tc.context,
+			}
+			require.NoError(t, modify.printDiff(before, after))
+			assert.Equal(t, "--- mysecret (live)\n+++ mysecret (edited)\n"+tc.expected, out.String())
+		})
+	}
+}
+
+func TestPrintEncodedDiff(t *testing.T) {
+	content := []byte(`{"name":"myapp"}`)
+	compressed, err := release.Encode(content)
+	require.NoError(t, err)
+	uncompressed := release.EncodeUncompressed(content)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{IOStreams: genericclioptions.IOStreams{Out: out}}
+	modify.printEncodedDiff(compressed, compressed)
+	modify.printEncodedDiff(compressed, uncompressed)
+
+	assert.Equal(t, fmt.Sprintf("encoded release: %d -> %d bytes (+0), layers base64+gzip -> base64+gzip (unchanged)\n", len(compressed), len(compressed))+
+		fmt.Sprintf("encoded release: %d -> %d bytes (%+d), layers base64+gzip -> base64 (changed)\n", len(compressed), len(uncompressed), len(uncompressed)-len(compressed)),
+		out.String())
+}
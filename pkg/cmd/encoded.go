@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// previewLength is the number of characters of a value shown by --show-encoded
+const previewLength = 32
+
+// runShowEncoded prints, per key of the secret, the value as stored and as decoded, to debug encoding issues.
+// The keys excluded by --include-keys or --exclude-keys aren't decoded.
+func (o *ModifySecretOptions) runShowEncoded() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	opts := o.modifyOptions()
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSIZE\tLAYERS\tENCODED\tDECODED")
+	for _, k := range keys {
+		encoded := secret.Data[k]
+		if !opts.DecodesKey(k) {
+			fmt.Fprintf(w, "%s\t%d\t-\t%s\t<not decoded>\n", k, len(encoded), preview(encoded))
+			continue
+		}
+
+		decoded, layers, err := release.Unwrap(encoded)
+		decodedPreview := preview(decoded)
+		if err != nil {
+			decodedPreview = fmt.Sprintf("<%v>", err)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", k, len(encoded), layers, preview(encoded), decodedPreview)
+	}
+
+	return w.Flush()
+}
+
+// preview quotes the beginning of a value on a single line, binary values are only described
+func preview(value []byte) string {
+	if !utf8.Valid(value) {
+		return fmt.Sprintf("<%d bytes of binary data>", len(value))
+	}
+
+	runes := []rune(string(value))
+	if len(runes) <= previewLength {
+		return strconv.Quote(string(runes))
+	}
+
+	return strconv.Quote(string(runes[:previewLength])) + "..."
+}
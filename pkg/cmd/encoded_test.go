@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunShowEncoded(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data: map[string][]byte{
+			"password": []byte("s3cr3t"),
+			"config":   release.EncodeUncompressed([]byte(`{"name":"myapp"}`)),
+			"cert":     {0x30, 0x82, 0xff, 0xfe},
+		},
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:   genericclioptions.IOStreams{Out: out},
+		kubeclient:  client,
+		secretName:  "mysecret",
+		namespace:   "mynamespace",
+		showEncoded: true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, `KEY       SIZE  LAYERS  ENCODED                     DECODED
+cert      4     plain   <4 bytes of binary data>    <4 bytes of binary data>
+config    24    base64  "eyJuYW1lIjoibXlhcHAifQ=="  "{\"name\":\"myapp\"}"
+password  6     plain   "s3cr3t"                    "s3cr3t"
+`, out.String())
+
+	out.Reset()
+	modify.excludeKeys = []string{"config"}
+	require.NoError(t, modify.Run())
+	assert.Contains(t, out.String(), "config    24    -       \"eyJuYW1lIjoibXlhcHAifQ==\"  <not decoded>\n")
+}
+
+func TestPreview(t *testing.T) {
+	assert.Equal(t, `"a\nb"`, preview([]byte("a\nb")))
+	assert.Equal(t, `"`+strings.Repeat("x", previewLength)+`"...`, preview([]byte(strings.Repeat("x", 100))))
+}
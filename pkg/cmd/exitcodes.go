@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Exit codes of the plugin, so scripts can branch on the failure without parsing its output
+const (
+	ExitGeneric      = 1
+	ExitNotFound     = 2
+	ExitForbidden    = 4
+	ExitDecodeFailed = 5
+	ExitConflict     = 6
+)
+
+// exitCodesHelp documents the exit codes in the command help
+const exitCodesHelp = `Exit codes:
+  0  success
+  1  generic failure
+  2  secret or release not found
+  4  permission denied
+  5  release could not be decoded
+  6  conflicting update of the secret`
+
+// ExitError is an error carrying the exit code of the plugin
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// newExitError classifies the error into an ExitError
+func newExitError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code := ExitGeneric
+	switch {
+	case apierrors.IsNotFound(err):
+		code = ExitNotFound
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		code = ExitForbidden
+	case errors.Is(err, release.ErrDecode):
+		code = ExitDecodeFailed
+	case apierrors.IsConflict(err):
+		code = ExitConflict
+	}
+
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the exit code of the plugin for the error returned by the command
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return ExitGeneric
+}
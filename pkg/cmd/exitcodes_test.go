@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExitCode(t *testing.T) {
+	secrets := schema.GroupResource{Resource: "secrets"}
+
+	testcases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "generic",
+			err:      fmt.Errorf("boom"),
+			expected: ExitGeneric,
+		},
+		{
+			name:     "not found",
+			err:      apierrors.NewNotFound(secrets, "mysecret"),
+			expected: ExitNotFound,
+		},
+		{
+			name:     "forbidden",
+			err:      apierrors.NewForbidden(secrets, "mysecret", fmt.Errorf("no rbac")),
+			expected: ExitForbidden,
+		},
+		{
+			name:     "unauthorized",
+			err:      apierrors.NewUnauthorized("bad token"),
+			expected: ExitForbidden,
+		},
+		{
+			name:     "decode failed",
+			err:      fmt.Errorf("%w: bad gzip", release.ErrDecode),
+			expected: ExitDecodeFailed,
+		},
+		{
+			name:     "wrapped conflict",
+			err:      fmt.Errorf("managed by helm: %w", apierrors.NewConflict(secrets, "mysecret", fmt.Errorf("stale"))),
+			expected: ExitConflict,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newExitError(tc.err)
+			assert.Equal(t, tc.expected, ExitCode(err))
+			assert.Equal(t, tc.err.Error(), err.Error())
+		})
+	}
+}
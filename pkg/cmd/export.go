@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// applyHelmExport writes to the file the release merged with the one reconstructed from a helm get all export.
+// The user supplied values of the export replace those of the release, other fields missing from the export are kept.
+func (o *ModifySecretOptions) applyHelmExport(file string) error {
+	export, err := os.ReadFile(o.helmExport)
+	if err != nil {
+		return err
+	}
+
+	exported, err := release.ParseExport(export)
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	content, err := release.FromFormat(o.format, current)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(exported, &fields)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := fields["config"]; ok {
+		content, err = jsonpatch.MergePatch(content, []byte(`{"config":null}`))
+		if err != nil {
+			return err
+		}
+	}
+
+	merged, err := jsonpatch.MergePatch(content, exported)
+	if err != nil {
+		return err
+	}
+
+	buffer, err := o.modifyOptions().Render(merged)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, buffer, 0644)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFromHelmExport(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "false")
+
+	export := filepath.Join(t.TempDir(), "export.txt")
+	require.NoError(t, os.WriteFile(export, []byte("NAME: myapp\nREVISION: 2\nUSER-SUPPLIED VALUES:\ntag: v2\nMANIFEST:\nimage: app:v2\n"), 0600))
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp","version":2,"chart":{"templates":[]},"config":{"debug":true,"tag":"v1"},"manifest":"image: app:v1\n"}`)},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		helmExport: export,
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","version":2,"chart":{"templates":[]},"config":{"tag":"v2"},"manifest":"image: app:v2\n"}`, decodeRelease(t, secret.Data["release"]))
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// historyEntry describes a revision of a release, like `helm history` does
+type historyEntry struct {
+	Revision    int    `json:"revision"`
+	Updated     string `json:"updated"`
+	Status      string `json:"status"`
+	Chart       string `json:"chart"`
+	AppVersion  string `json:"app_version"`
+	Description string `json:"description"`
+}
+
+// runHistory prints the revisions of the release
+func (o *ModifySecretOptions) runHistory() error {
+	items, err := o.driver.List(context.TODO(), o.namespace, fmt.Sprintf("%s,name=%s", releaseSelector, o.secretName), o.fieldSelector)
+	if err != nil {
+		return err
+	}
+
+	history := []historyEntry{}
+	for _, secret := range items {
+		rel, err := o.parsedRelease(&secret)
+		if err != nil {
+			logrus.Warnf("skipping secret %q: %v", secret.Name, err)
+			continue
+		}
+
+		history = append(history, historyEntry{
+			Revision:    rel.Version,
+			Updated:     rel.Info.LastDeployed,
+			Status:      rel.Info.Status,
+			Chart:       fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version),
+			AppVersion:  rel.Chart.Metadata.AppVersion,
+			Description: rel.Info.Description,
+		})
+	}
+
+	if len(history) == 0 {
+		return apierrors.NewNotFound(schema.GroupResource{Group: "helm.sh", Resource: "releases"}, o.secretName)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Revision < history[j].Revision
+	})
+
+	if o.output == "json" {
+		return o.writeJSON(history)
+	}
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tUPDATED\tSTATUS\tCHART\tAPP VERSION\tDESCRIPTION")
+	for _, entry := range history {
+		updated := entry.Updated
+		if t, err := time.Parse(time.RFC3339, entry.Updated); err == nil {
+			updated = t.Format(time.ANSIC)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", entry.Revision, updated, entry.Status, entry.Chart, entry.AppVersion, entry.Description)
+	}
+
+	return w.Flush()
+}
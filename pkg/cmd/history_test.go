@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunHistory(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","version":2,"info":{"status":"deployed","description":"Upgrade complete","last_deployed":"2023-10-02T08:30:00Z"},"chart":{"metadata":{"name":"app","version":"1.1.0","appVersion":"2.0"}}}`),
+		releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"superseded","description":"Install complete","last_deployed":"2023-10-01T12:00:00Z"},"chart":{"metadata":{"name":"app","version":"1.0.0","appVersion":"1.0"}}}`),
+		releaseSecret(t, namespace, "other", 1, `{"name":"other","version":1,"info":{"status":"deployed"}}`),
+	)
+
+	testcases := []struct {
+		name       string
+		output     string
+		jsonIndent int
+		expected   string
+	}{
+		{
+			name:   "table",
+			output: "",
+			expected: `REVISION  UPDATED                   STATUS      CHART      APP VERSION  DESCRIPTION
+1         Sun Oct  1 12:00:00 2023  superseded  app-1.0.0  1.0          Install complete
+2         Mon Oct  2 08:30:00 2023  deployed    app-1.1.0  2.0          Upgrade complete
+`,
+		},
+		{
+			name:   "json",
+			output: "json",
+			expected: `[{"revision":1,"updated":"2023-10-01T12:00:00Z","status":"superseded","chart":"app-1.0.0","app_version":"1.0","description":"Install complete"},` +
+				`{"revision":2,"updated":"2023-10-02T08:30:00Z","status":"deployed","chart":"app-1.1.0","app_version":"2.0","description":"Upgrade complete"}]
+`,
+		},
+		{
+			name:       "indented json",
+			output:     "json",
+			jsonIndent: 1,
+			expected: `[
+ {
+  "revision": 1,
+  "updated": "2023-10-01T12:00:00Z",
+  "status": "superseded",
+  "chart": "app-1.0.0",
+  "app_version": "1.0",
+  "description": "Install complete"
+ },
+ {
+  "revision": 2,
+  "updated": "2023-10-02T08:30:00Z",
+  "status": "deployed",
+  "chart": "app-1.1.0",
+  "app_version": "2.0",
+  "description": "Upgrade complete"
+ }
+]
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			modify := ModifySecretOptions{
+				IOStreams:  genericclioptions.IOStreams{Out: out},
+				kubeclient: client,
+				secretName: "myapp",
+				namespace:  namespace,
+				history:    true,
+				output:     tc.output,
+				jsonIndent: tc.jsonIndent,
+			}
+			require.NoError(t, modify.Run())
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+
+	modify := ModifySecretOptions{kubeclient: client, secretName: "missing", namespace: namespace, history: true}
+	assert.EqualError(t, modify.Run(), `releases.helm.sh "missing" not found`)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+)
+
+// parseImageReplacement parses the OLD=NEW image references of --replace-image
+func parseImageReplacement(arg string) (string, string, error) {
+	old, new, ok := strings.Cut(arg, "=")
+	if !ok || old == "" || new == "" {
+		return "", "", fmt.Errorf("invalid --replace-image %q, expected OLD=NEW like myrepo/app:v1=myrepo/app:v2", arg)
+	}
+	if old == new {
+		return "", "", fmt.Errorf("invalid --replace-image %q, both images are the same", arg)
+	}
+
+	return old, new, nil
+}
+
+// runReplaceImage replaces an image reference in the values of the release, and in its manifest
+// with --replace-image-manifest, without opening an editor
+func (o *ModifySecretOptions) runReplaceImage() error {
+	old, new, err := parseImageReplacement(o.imageReplacement)
+	if err != nil {
+		return err
+	}
+
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	err = secrets.CheckMutable(secret)
+	if err != nil {
+		return err
+	}
+
+	warnIfManaged(secret)
+
+	content, key, err := o.releaseOf(secret)
+	if err != nil {
+		return err
+	}
+
+	edited, replaced, err := release.ReplaceImage(content, old, new, o.imageInManifest)
+	if err != nil {
+		return err
+	}
+
+	if len(replaced) == 0 {
+		return fmt.Errorf("image %q not found in release %q", old, o.secretName)
+	}
+	for _, path := range replaced {
+		logrus.Infof("%s: %q -> %q", path, old, new)
+	}
+
+	secret.Data[key], err = o.encode(edited)
+	if err != nil {
+		return err
+	}
+	o.applyMetadata(secret)
+
+	if o.dryRun {
+		logrus.Infof("%d occurrences of image %q replaced in release %q (dry run)", len(replaced), old, o.secretName)
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.Update(context.TODO(), secret, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("%d occurrences of image %q replaced in release %q", len(replaced), old, o.secretName)
+	return nil
+}
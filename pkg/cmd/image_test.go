@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunReplaceImage(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	original := `{"name":"myapp","config":{"image":{"repository":"myrepo/app","tag":"v1"}},"manifest":"image: myrepo/app:v1\n"}`
+	secret := releaseSecret(t, namespace, "myapp", 1, original)
+	client := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient:       client,
+		secretName:       secret.Name,
+		namespace:        namespace,
+		imageReplacement: "myrepo/app:v1=myrepo/app:v2",
+		imageInManifest:  true,
+		dryRun:           true,
+	}
+	require.NoError(t, modify.Run())
+	stored, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, original, decodeRelease(t, stored.Data["release"]))
+
+	modify.dryRun = false
+	require.NoError(t, modify.Run())
+	stored, err = client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","config":{"image":{"repository":"myrepo/app","tag":"v2"}},"manifest":"image: myrepo/app:v2\n"}`, decodeRelease(t, stored.Data["release"]))
+
+	assert.EqualError(t, modify.Run(), `image "myrepo/app:v1" not found in release "sh.helm.release.v1.myapp.v1"`)
+}
+
+func TestRunReplaceImageReleaseKey(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	secret := releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","config":{"image":"myrepo/app:v1"}}`)
+	secret.Data = map[string][]byte{"payload": secret.Data["release"], "token": []byte("s3cr3t")}
+	client := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient:       client,
+		secretName:       secret.Name,
+		namespace:        namespace,
+		releaseKey:       "release",
+		imageReplacement: "myrepo/app:v1=myrepo/app:v2",
+	}
+	require.NoError(t, modify.Run())
+
+	stored, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","config":{"image":"myrepo/app:v2"}}`, decodeRelease(t, stored.Data["payload"]))
+	assert.Equal(t, "s3cr3t", string(stored.Data["token"]))
+	assert.NotContains(t, stored.Data, "release")
+}
+
+func TestParseImageReplacement(t *testing.T) {
+	old, new, err := parseImageReplacement("myrepo/app:v1=myrepo/app:v2")
+	require.NoError(t, err)
+	assert.Equal(t, "myrepo/app:v1", old)
+	assert.Equal(t, "myrepo/app:v2", new)
+
+	_, _, err = parseImageReplacement("myrepo/app:v1")
+	assert.EqualError(t, err, `invalid --replace-image "myrepo/app:v1", expected OLD=NEW like myrepo/app:v1=myrepo/app:v2`)
+
+	_, _, err = parseImageReplacement("myrepo/app:v1=myrepo/app:v1")
+	assert.EqualError(t, err, `invalid --replace-image "myrepo/app:v1=myrepo/app:v1", both images are the same`)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// runSetKeys sets the given keys of the secret without opening an editor.
+// Existing keys are re-encoded with the layers they were stored with, new keys are stored as is.
+func (o *ModifySecretOptions) runSetKeys(values map[string][]byte) error {
+	_, secret, err := o.setKeys(values)
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		logrus.Infof("secret %q edited (dry run)", o.secretName)
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.Update(context.TODO(), secret, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("secret %q edited", o.secretName)
+	return nil
+}
+
+// setKeys reads the secret and returns it along with a copy having the given keys set, without updating it
+func (o *ModifySecretOptions) setKeys(values map[string][]byte) (*v1.Secret, *v1.Secret, error) {
+	original, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = secrets.CheckMutable(original)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnIfManaged(original)
+
+	err = o.confirmClearedKeys(original, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret := original.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	for k, v := range values {
+		layers := release.Layers{}
+		if existing, ok := secret.Data[k]; ok {
+			_, layers, err = release.Unwrap(existing)
+			if err != nil {
+				return nil, nil, release.WithKey(err, k)
+			}
+		}
+
+		encoded, err := release.Wrap(v, layers)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode key %q: %v", k, err)
+		}
+
+		logrus.Infof("setting key %q (%s)", k, layers)
+		secret.Data[k] = encoded
+	}
+	o.applyMetadata(secret)
+
+	return original, secret, nil
+}
+
+// keyValues gathers the values given with --from-literal and --from-file
+func (o *ModifySecretOptions) keyValues() (map[string][]byte, error) {
+	values := make(map[string][]byte, len(o.literals)+len(o.fileArgs))
+	for k, v := range o.literals {
+		values[k] = []byte(v)
+	}
+
+	for _, arg := range o.fileArgs {
+		k, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			k, path = filepath.Base(arg), arg
+		}
+
+		if _, exists := values[k]; exists {
+			return nil, fmt.Errorf("key %q is set more than once", k)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = content
+	}
+
+	return values, nil
+}
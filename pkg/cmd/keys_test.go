@@ -0,0 +1,453 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestFromLiteral(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("old"),
+			"release":  encodeRelease(t, `{"name":"myapp"}`),
+		},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		literals: map[string]string{
+			"password": "newpass",
+			"token":    "abc",
+			"release":  `{"name":"renamed"}`,
+		},
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", string(secret.Data["username"]))
+	assert.Equal(t, "newpass", string(secret.Data["password"]))
+	assert.Equal(t, "abc", string(secret.Data["token"]))
+	assert.Equal(t, `{"name":"renamed"}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestFromLiteralClearedKeys(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			"password": []byte("old"),
+			"token":    {},
+		},
+	})
+
+	errOut := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{In: bytes.NewBufferString("no\n"), ErrOut: errOut},
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		literals:   map[string]string{"password": "", "token": ""},
+	}
+	assert.EqualError(t, modify.Run(), `clearing keys not confirmed, secret "mysecret" left untouched`)
+	assert.Equal(t, "the following keys of secret \"mysecret\" will be cleared:\n  password (3 bytes)\ntype yes to clear them: ", errOut.String())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(secret.Data["password"]))
+
+	modify.IOStreams.In = bytes.NewBufferString("yes\n")
+	modify.answers = nil
+	require.NoError(t, modify.Run())
+	secret, err = client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, secret.Data["password"])
+}
+
+func TestEditorKeepsOtherKeys(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "sed -i= s/value/updated/")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			"release":  encodeRelease(t, `{"name":"value"}`),
+			"password": []byte("old"),
+		},
+	})
+
+	errOut := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{ErrOut: errOut},
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+	}
+	require.NoError(t, modify.Run())
+	assert.Empty(t, errOut.String())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"updated"}`, decodeRelease(t, secret.Data["release"]))
+	assert.Equal(t, "old", string(secret.Data["password"]))
+}
+
+func TestFromLiteralMixedLayers(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+
+	single := encodeRelease(t, `{"name":"single"}`)
+	double := release.EncodeUncompressed(encodeRelease(t, `{"name":"double"}`))
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			"single":   single,
+			"double":   double,
+			"password": []byte("old"),
+		},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		literals: map[string]string{
+			"single":   `{"name":"single2"}`,
+			"double":   `{"name":"double2"}`,
+			"password": "newpass",
+		},
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	for key, expected := range map[string]release.Layers{
+		"single":   {release.LayerBase64, release.LayerGzip},
+		"double":   {release.LayerBase64, release.LayerBase64, release.LayerGzip},
+		"password": {},
+	} {
+		content, layers, err := release.Unwrap(secret.Data[key])
+		require.NoError(t, err)
+		assert.Equal(t, expected, layers, key)
+		assert.Equal(t, modify.literals[key], string(content), key)
+	}
+}
+
+func TestFromLiteralBadKey(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data: map[string][]byte{
+			"good": encodeRelease(t, `{"name":"myapp"}`),
+			"bad":  release.EncodeUncompressed([]byte{0x1f, 0x8b, 0x08, 0x00}),
+		},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		literals: map[string]string{
+			"good": `{"name":"renamed"}`,
+			"bad":  `{"name":"renamed"}`,
+		},
+	}
+	err := modify.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to gzip-decode key "bad"`)
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"myapp"}`, decodeRelease(t, secret.Data["good"]))
+}
+
+func TestFromFile(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+
+	dir := t.TempDir()
+	cert := []byte{0x30, 0x82, 0x00, 0xff, 0xfe}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.crt"), cert, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kubeconfig"), []byte("apiVersion: v1"), 0600))
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string][]byte{"tls.crt": []byte("old")},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		fileArgs: []string{
+			"tls.crt=" + filepath.Join(dir, "new.crt"),
+			filepath.Join(dir, "kubeconfig"),
+		},
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, cert, secret.Data["tls.crt"])
+	assert.Equal(t, "apiVersion: v1", string(secret.Data["kubeconfig"]))
+
+	modify.literals = map[string]string{"kubeconfig": "x"}
+	assert.EqualError(t, modify.Run(), `key "kubeconfig" is set more than once`)
+}
+
+func TestFromLiteralPattern(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-api", Namespace: namespace}, Data: map[string][]byte{"env": []byte("dev")}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-web", Namespace: namespace}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace}},
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		secretName: "app-*",
+		namespace:  namespace,
+		literals:   map[string]string{"env": "prod"},
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, `SECRET   RESULT
+app-api  edited
+app-web  edited
+`, out.String())
+
+	for name, expected := range map[string]string{"app-api": "prod", "app-web": "prod", "other": ""} {
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(secret.Data["env"]), name)
+	}
+
+	modify.secretName = "nothing-*"
+	assert.EqualError(t, modify.Run(), `no secret in namespace "mynamespace" matches "nothing-*"`)
+
+	modify.literals = nil
+	modify.secretName = "app-*"
+	assert.EqualError(t, modify.Validate(), "a secret name pattern is only supported with --from-literal or --from-file, editing several secrets at once is ambiguous")
+}
+
+func TestFromLiteralPatternAtomic(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	immutable := true
+	newClient := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-api", Namespace: namespace, Labels: map[string]string{"team": "api"}}, Data: map[string][]byte{"env": []byte("dev")}},
+			&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-web", Namespace: namespace}, Data: map[string][]byte{"env": []byte("dev")}},
+			&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-worker", Namespace: namespace}, Data: map[string][]byte{"env": []byte("dev")}},
+		)
+	}
+	assertEnv := func(t *testing.T, client *fake.Clientset, expected map[string]string) {
+		for name, env := range expected {
+			secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, env, string(secret.Data["env"]), name)
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		atomic   bool
+		setup    func(client *fake.Clientset)
+		output   string
+		err      string
+		expected map[string]string
+	}{
+		{
+			name:   "best effort applies what is valid",
+			setup:  failUpdateOf("app-web"),
+			output: "SECRET      RESULT\napp-api     edited\napp-web     failed: connection refused\napp-worker  edited\n",
+			err:    "1 of 3 secrets failed",
+			expected: map[string]string{
+				"app-api": "prod", "app-web": "dev", "app-worker": "prod",
+			},
+		},
+		{
+			name:   "atomic rolls back on update failure",
+			atomic: true,
+			setup:  failUpdateOf("app-web"),
+			output: "SECRET      RESULT\napp-api     rolled back\napp-web     failed: connection refused\napp-worker  not edited\n",
+			err:    "1 of 3 secrets failed, none was edited",
+			expected: map[string]string{
+				"app-api": "dev", "app-web": "dev", "app-worker": "dev",
+			},
+		},
+		{
+			name:   "atomic reports the secrets it fails to roll back",
+			atomic: true,
+			setup: func(client *fake.Clientset) {
+				updates := map[string]int{}
+				client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					name := action.(k8stesting.UpdateAction).GetObject().(*v1.Secret).Name
+					updates[name]++
+					if name == "app-worker" || updates[name] > 1 {
+						return true, nil, fmt.Errorf("connection refused")
+					}
+					return false, nil, nil
+				})
+			},
+			output: "SECRET      RESULT\napp-api     edited, roll back failed\napp-web     edited, roll back failed\napp-worker  failed: connection refused\n",
+			err:    `1 of 3 secrets failed, and 2 could not be rolled back and keep the edit: "app-api", "app-web"`,
+			expected: map[string]string{
+				"app-api": "prod", "app-web": "prod", "app-worker": "dev",
+			},
+		},
+		{
+			name:   "atomic validates all before applying",
+			atomic: true,
+			setup: func(client *fake.Clientset) {
+				secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), "app-worker", metav1.GetOptions{})
+				require.NoError(t, err)
+				secret.Immutable = &immutable
+				_, err = client.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+				require.NoError(t, err)
+			},
+			output: "SECRET      RESULT\napp-api     not edited\napp-web     not edited\napp-worker  failed: secret \"app-worker\" is immutable, the API server rejects any change of its data: delete and recreate it to edit it\n",
+			err:    "1 of 3 secrets failed, none was edited",
+			expected: map[string]string{
+				"app-api": "dev", "app-web": "dev", "app-worker": "dev",
+			},
+		},
+		{
+			name:   "atomic applies all",
+			atomic: true,
+			setup:  func(client *fake.Clientset) {},
+			output: "SECRET      RESULT\napp-api     edited\napp-web     edited\napp-worker  edited\n",
+			expected: map[string]string{
+				"app-api": "prod", "app-web": "prod", "app-worker": "prod",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newClient()
+			tc.setup(client)
+
+			out := &bytes.Buffer{}
+			modify := ModifySecretOptions{
+				IOStreams:  genericclioptions.IOStreams{Out: out},
+				kubeclient: client,
+				secretName: "app-*",
+				namespace:  namespace,
+				literals:   map[string]string{"env": "prod"},
+				atomic:     tc.atomic,
+			}
+			err := modify.Run()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.output, out.String())
+			assertEnv(t, client, tc.expected)
+		})
+	}
+
+	modify := ModifySecretOptions{secretName: "app-api", atomic: true}
+	assert.EqualError(t, modify.Validate(), "--atomic requires a secret name pattern")
+}
+
+func TestFromLiteralPatternAtomicConfirm(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-api", Namespace: namespace}, Data: map[string][]byte{"env": []byte("dev")}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "app-web", Namespace: namespace}, Data: map[string][]byte{"env": []byte("dev")}},
+	)
+
+	// the answers are piped at once, each prompt must find its own
+	modify := ModifySecretOptions{
+		IOStreams:          genericclioptions.IOStreams{In: bytes.NewBufferString("app-api\napp-web\n"), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+		kubeclient:         client,
+		secretName:         "app-*",
+		namespace:          namespace,
+		literals:           map[string]string{"env": "prod"},
+		atomic:             true,
+		requireConfirmName: true,
+	}
+	require.NoError(t, modify.Run())
+
+	for _, name := range []string{"app-api", "app-web"} {
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "prod", string(secret.Data["env"]), name)
+	}
+}
+
+// failUpdateOf makes the updates of the secret fail
+func failUpdateOf(name string) func(client *fake.Clientset) {
+	return func(client *fake.Clientset) {
+		client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.(k8stesting.UpdateAction).GetObject().(*v1.Secret).Name == name {
+				return true, nil, fmt.Errorf("connection refused")
+			}
+			return false, nil, nil
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// runFixLabels reconciles the labels Helm duplicates from the release payload onto its secret
+func (o *ModifySecretOptions) runFixLabels() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	rel, err := o.parsedRelease(secret)
+	if err != nil {
+		return err
+	}
+
+	expected, err := releaseLabels(rel)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(expected))
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	changed := map[string]string{}
+	for _, k := range keys {
+		if secret.Labels[k] == expected[k] {
+			continue
+		}
+		logrus.Infof("label %q: %q -> %q", k, secret.Labels[k], expected[k])
+		changed[k] = expected[k]
+	}
+
+	if len(changed) == 0 {
+		logrus.Infof("labels of secret %q already match its release", o.secretName)
+		return nil
+	}
+
+	if o.dryRun {
+		logrus.Infof("labels of secret %q fixed (dry run)", o.secretName)
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.PatchMetadata(context.TODO(), o.secretName, o.namespace, changed, nil, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("labels of secret %q fixed", o.secretName)
+	return nil
+}
+
+// releaseLabels computes the labels Helm sets on the secret of a release
+func releaseLabels(rel *release.Release) (map[string]string, error) {
+	labels := map[string]string{
+		"status":  rel.Info.Status,
+		"version": strconv.Itoa(rel.Version),
+	}
+
+	if rel.Info.LastDeployed != "" {
+		lastDeployed, err := time.Parse(time.RFC3339, rel.Info.LastDeployed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid info.last_deployed: %v", err)
+		}
+		labels["modifiedAt"] = strconv.FormatInt(lastDeployed.Unix(), 10)
+	}
+
+	return labels, nil
+}
+
+// labeledRelease returns a release holding only the name, version and status Helm duplicates
+// in the name, version and status labels of its secret, without decoding it.
+// It returns false when one of these labels is missing or invalid.
+func labeledRelease(secret *v1.Secret) (*release.Release, bool) {
+	name, status := secret.Labels["name"], secret.Labels["status"]
+	version, err := strconv.Atoi(secret.Labels["version"])
+	if name == "" || status == "" || err != nil {
+		return nil, false
+	}
+
+	return &release.Release{Name: name, Namespace: secret.Namespace, Version: version, Info: release.Info{Status: status}}, true
+}
+
+// listedRelease returns the release of the secret for --list and --summary. With --from-labels, it is read
+// from the labels of the secret, and only decoded when they are incomplete.
+func (o *ModifySecretOptions) listedRelease(secret *v1.Secret) (*release.Release, error) {
+	if o.fromLabels {
+		if rel, ok := labeledRelease(secret); ok {
+			return rel, nil
+		}
+	}
+
+	return o.parsedRelease(secret)
+}
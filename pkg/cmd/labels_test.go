@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunFixLabels(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	secret := releaseSecret(t, namespace, "myapp", 3, `{"name":"myapp","version":3,"info":{"status":"deployed","last_deployed":"2023-10-01T12:00:00Z"}}`)
+	secret.Labels["status"] = "pending-upgrade"
+	secret.Labels["version"] = "2"
+	client := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: secret.Name,
+		namespace:  namespace,
+		fixLabels:  true,
+	}
+	require.NoError(t, modify.Run())
+
+	fixed, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"owner":      "helm",
+		"name":       "myapp",
+		"status":     "deployed",
+		"version":    "3",
+		"modifiedAt": "1696161600",
+	}, fixed.Labels)
+	assert.Equal(t, secret.Data, fixed.Data)
+	assertVerbs(t, client.Actions(), "get", "patch", "get")
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// releaseSelector selects the secrets Helm stores releases in
+const releaseSelector = "owner=helm"
+
+// runList prints the Helm releases stored in the namespaces to operate in
+func (o *ModifySecretOptions) runList() error {
+	var tmpl *template.Template
+	if o.outputTemplate != "" {
+		var err error
+		tmpl, err = parseOutputTemplate(o.outputTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.output == "jsonl" {
+		return o.eachNamespaceReleases(o.printJSONLines)
+	}
+
+	items, err := o.releaseSecrets()
+	if err != nil {
+		return err
+	}
+
+	if tmpl != nil {
+		return o.printTemplate(tmpl, items)
+	}
+
+	wide := o.output == "wide"
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	if wide {
+		fmt.Fprintln(w, "NAME\tREVISION\tSTATUS\tNAMESPACE\tSECRET\tCHART\tAPP VERSION")
+	} else {
+		fmt.Fprintln(w, "NAME\tREVISION\tSTATUS\tNAMESPACE\tSECRET")
+	}
+	for i := range items {
+		secret := &items[i]
+		rel, err := o.listedRelease(secret)
+		if err != nil {
+			logrus.Warnf("skipping secret %q: %v", secret.Name, err)
+			continue
+		}
+
+		if !o.deployedSince(rel) {
+			continue
+		}
+
+		namespace := secret.Namespace
+		if rel.Namespace != "" && rel.Namespace != secret.Namespace {
+			namespace = fmt.Sprintf("%s (release: %s)", secret.Namespace, rel.Namespace)
+		}
+
+		if wide {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", rel.Name, rel.Version, rel.Info.Status, namespace, secret.Name, chartColumn(rel), rel.Chart.Metadata.AppVersion)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", rel.Name, rel.Version, rel.Info.Status, namespace, secret.Name)
+	}
+
+	return w.Flush()
+}
+
+// chartColumn returns the chart of the release as name-version, like helm list shows it
+func chartColumn(rel *release.Release) string {
+	if rel.Chart.Metadata.Name == "" {
+		return ""
+	}
+
+	return rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+}
+
+// listEntry is a release printed by --list -o jsonl
+type listEntry struct {
+	Name             string `json:"name"`
+	Revision         int    `json:"revision"`
+	Status           string `json:"status"`
+	Namespace        string `json:"namespace"`
+	ReleaseNamespace string `json:"release_namespace,omitempty"`
+	Secret           string `json:"secret"`
+}
+
+// printJSONLines prints each release as a JSON object on its own line, as soon as it is decoded
+func (o *ModifySecretOptions) printJSONLines(items []v1.Secret) error {
+	encoder := json.NewEncoder(o.IOStreams.Out)
+	for i := range items {
+		secret := &items[i]
+		rel, err := o.listedRelease(secret)
+		if err != nil {
+			logrus.Warnf("skipping secret %q: %v", secret.Name, err)
+			continue
+		}
+
+		if !o.deployedSince(rel) {
+			continue
+		}
+
+		entry := listEntry{Name: rel.Name, Revision: rel.Version, Status: rel.Info.Status, Namespace: secret.Namespace, Secret: secret.Name}
+		if rel.Namespace != secret.Namespace {
+			entry.ReleaseNamespace = rel.Namespace
+		}
+
+		err = encoder.Encode(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printTemplate executes the template of --output-template against each release, one per line
+func (o *ModifySecretOptions) printTemplate(tmpl *template.Template, items []v1.Secret) error {
+	for _, secret := range items {
+		rel, err := o.parsedRelease(&secret)
+		if err != nil {
+			logrus.Warnf("skipping secret %q: %v", secret.Name, err)
+			continue
+		}
+
+		if !o.deployedSince(rel) {
+			continue
+		}
+
+		err = tmpl.Execute(o.IOStreams.Out, rel)
+		if err != nil {
+			return fmt.Errorf("executing --output-template on secret %q: %v", secret.Name, err)
+		}
+		fmt.Fprintln(o.IOStreams.Out)
+	}
+
+	return nil
+}
+
+// releaseSecrets returns the secrets of the Helm releases in the namespaces to operate in,
+// sorted by namespace, release and revision
+func (o *ModifySecretOptions) releaseSecrets() ([]v1.Secret, error) {
+	items := []v1.Secret{}
+	err := o.eachNamespaceReleases(func(nsItems []v1.Secret) error {
+		items = append(items, nsItems...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortReleaseSecrets(items)
+	return items, nil
+}
+
+// eachNamespaceReleases lists the secrets of the Helm releases namespace by namespace, and calls fn
+// with those of each namespace sorted by release and revision, so they can be processed as they come
+func (o *ModifySecretOptions) eachNamespaceReleases(fn func([]v1.Secret) error) error {
+	namespaces, err := o.namespaces()
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		nsItems, err := o.driver.List(context.TODO(), namespace, releaseSelector, o.fieldSelector)
+		if apierrors.IsForbidden(err) && o.namespaceSelector != "" {
+			logrus.Warnf("skipping namespace %q: %v", namespace, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		sortReleaseSecrets(nsItems)
+		err = fn(nsItems)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortReleaseSecrets sorts the secrets of Helm releases by namespace, release and revision
+func sortReleaseSecrets(items []v1.Secret) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		if items[i].Labels["name"] != items[j].Labels["name"] {
+			return items[i].Labels["name"] < items[j].Labels["name"]
+		}
+		vi, _ := strconv.Atoi(items[i].Labels["version"])
+		vj, _ := strconv.Atoi(items[j].Labels["version"])
+		return vi < vj
+	})
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRunList(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","namespace":"mynamespace","version":2,"info":{"status":"deployed"}}`),
+		releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","namespace":"mynamespace","version":1,"info":{"status":"superseded"}}`),
+		releaseSecret(t, namespace, "other", 1, `{"name":"other","namespace":"elsewhere","version":1,"info":{"status":"failed"}}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		namespace:  namespace,
+		list:       true,
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `NAME   REVISION  STATUS      NAMESPACE                         SECRET
+myapp  1         superseded  mynamespace                       sh.helm.release.v1.myapp.v1
+myapp  2         deployed    mynamespace                       sh.helm.release.v1.myapp.v2
+other  1         failed      mynamespace (release: elsewhere)  sh.helm.release.v1.other.v1
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRunListWide(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","namespace":"mynamespace","version":2,"info":{"status":"deployed"},"chart":{"metadata":{"name":"webapp","version":"1.4.0","appVersion":"2.1"}}}`),
+		releaseSecret(t, namespace, "other", 1, `{"name":"other","namespace":"mynamespace","version":1,"info":{"status":"failed"}}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		namespace:  namespace,
+		list:       true,
+		output:     "wide",
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `NAME   REVISION  STATUS    NAMESPACE    SECRET                       CHART         APP VERSION
+myapp  2         deployed  mynamespace  sh.helm.release.v1.myapp.v2  webapp-1.4.0  2.1
+other  1         failed    mynamespace  sh.helm.release.v1.other.v1                
+`
+	assert.Equal(t, expected, out.String())
+
+	modify = ModifySecretOptions{history: true, output: "wide", format: "yaml", args: []string{"myapp"}, secretName: "myapp"}
+	assert.EqualError(t, modify.Validate(), "-o wide is only supported with --list, without --output-template")
+
+	modify = ModifySecretOptions{summary: true, output: "wide"}
+	assert.EqualError(t, modify.Validate(), "-o wide is only supported with --list, without --output-template")
+
+	modify = ModifySecretOptions{list: true, output: "wide", outputTemplate: "{{.Name}}"}
+	assert.EqualError(t, modify.Validate(), "-o wide is only supported with --list, without --output-template")
+}
+
+func TestValidateListOutput(t *testing.T) {
+	modify := ModifySecretOptions{list: true, output: "xml"}
+	assert.EqualError(t, modify.Validate(), `unsupported output format "xml"`)
+
+	modify = ModifySecretOptions{list: true, output: "jsonl", outputTemplate: "{{.Name}}"}
+	assert.EqualError(t, modify.Validate(), "-o jsonl is only supported with --list or --summary, without --output-template")
+
+	modify = ModifySecretOptions{summary: true, output: "jsonl"}
+	assert.NoError(t, modify.Validate())
+
+	for _, modify := range []ModifySecretOptions{
+		{list: true, fromLabels: true, since: time.Hour},
+		{list: true, fromLabels: true, output: "wide"},
+		{list: true, fromLabels: true, outputTemplate: "{{.Name}}"},
+	} {
+		assert.EqualError(t, modify.Validate(), "--from-labels cannot be used with --since, -o wide or --output-template, they need the decoded release")
+	}
+}
+
+func TestRunListNamespaceSelector(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		namespace("payments-eu", map[string]string{"team": "payments"}),
+		namespace("payments-us", map[string]string{"team": "payments"}),
+		namespace("restricted", map[string]string{"team": "payments"}),
+		namespace("search", map[string]string{"team": "search"}),
+		releaseSecret(t, "payments-us", "api", 1, `{"name":"api","namespace":"payments-us","version":1,"info":{"status":"deployed"}}`),
+		releaseSecret(t, "payments-eu", "api", 3, `{"name":"api","namespace":"payments-eu","version":3,"info":{"status":"deployed"}}`),
+		releaseSecret(t, "search", "api", 1, `{"name":"api","namespace":"search","version":1,"info":{"status":"deployed"}}`),
+	)
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "restricted" {
+			return true, nil, apierrors.NewForbidden(v1.Resource("secrets"), "", fmt.Errorf("no rbac"))
+		}
+		return false, nil, nil
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:         genericclioptions.IOStreams{Out: out},
+		kubeclient:        client,
+		list:              true,
+		namespaceSelector: "team=payments",
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `NAME  REVISION  STATUS    NAMESPACE    SECRET
+api   3         deployed  payments-eu  sh.helm.release.v1.api.v3
+api   1         deployed  payments-us  sh.helm.release.v1.api.v1
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRunListFieldSelector(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"deployed"}}`),
+	)
+
+	modify := ModifySecretOptions{
+		IOStreams:     genericclioptions.IOStreams{Out: &bytes.Buffer{}},
+		kubeclient:    client,
+		namespace:     namespace,
+		list:          true,
+		fieldSelector: "type=helm.sh/release.v1",
+	}
+	require.NoError(t, modify.Run())
+
+	require.Len(t, client.Actions(), 1)
+	restrictions := client.Actions()[0].(k8stesting.ListAction).GetListRestrictions()
+	assert.Equal(t, "owner=helm", restrictions.Labels.String())
+	assert.Equal(t, "type=helm.sh/release.v1", restrictions.Fields.String())
+}
+
+func TestRunListSince(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	recent := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","version":2,"info":{"status":"deployed","last_deployed":"`+recent+`"}}`),
+		releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"superseded","last_deployed":"`+old+`"}}`),
+		releaseSecret(t, namespace, "other", 1, `{"name":"other","version":1,"info":{"status":"deployed"}}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		namespace:  namespace,
+		list:       true,
+		since:      time.Hour,
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `NAME   REVISION  STATUS    NAMESPACE    SECRET
+myapp  2         deployed  mynamespace  sh.helm.release.v1.myapp.v2
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestRunListCachedList(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	for _, storage := range []string{secrets.StorageSecret, secrets.StorageConfigMap} {
+		modify := ModifySecretOptions{
+			IOStreams:  genericclioptions.IOStreams{Out: &bytes.Buffer{}},
+			kubeclient: fake.NewSimpleClientset(),
+			namespace:  "mynamespace",
+			storage:    storage,
+			list:       true,
+			cachedList: true,
+		}
+		require.NoError(t, modify.Run())
+
+		switch driver := modify.driver.(type) {
+		case *secrets.SecretDriver:
+			assert.True(t, driver.ListFromCache)
+		case *secrets.ConfigMapDriver:
+			assert.True(t, driver.ListFromCache)
+		default:
+			t.Fatalf("unexpected driver %T", driver)
+		}
+	}
+}
+
+// namespace builds a namespace with the given labels
+func namespace(name string, labels map[string]string) *v1.Namespace {
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestRunListFromLabels(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	labeled := releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","version":2,"info":{"status":"deployed"}}`)
+	labeled.Labels["status"] = "deployed"
+	labeled.Data["release"] = []byte("not decoded")
+	client := fake.NewSimpleClientset(
+		labeled,
+		releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"superseded"}}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		namespace:  namespace,
+		list:       true,
+		fromLabels: true,
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `NAME   REVISION  STATUS      NAMESPACE    SECRET
+myapp  1         superseded  mynamespace  sh.helm.release.v1.myapp.v1
+myapp  2         deployed    mynamespace  sh.helm.release.v1.myapp.v2
+`
+	assert.Equal(t, expected, out.String())
+
+	out.Reset()
+	modify.fromLabels = false
+	require.NoError(t, modify.Run())
+	assert.NotContains(t, out.String(), "myapp.v2")
+}
+
+func TestRunListJSONLines(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		namespace("payments", map[string]string{"team": "payments"}),
+		namespace("search", map[string]string{"team": "payments"}),
+		releaseSecret(t, "search", "api", 1, `{"name":"api","namespace":"search","version":1,"info":{"status":"deployed"}}`),
+		releaseSecret(t, "payments", "api", 2, `{"name":"api","namespace":"payments","version":2,"info":{"status":"deployed"}}`),
+		releaseSecret(t, "payments", "api", 1, `{"name":"api","namespace":"elsewhere","version":1,"info":{"status":"superseded"}}`),
+	)
+	lists := 0
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		return false, nil, nil
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:         genericclioptions.IOStreams{Out: out},
+		kubeclient:        client,
+		namespaceSelector: "team=payments",
+		list:              true,
+		output:            "jsonl",
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `{"name":"api","revision":1,"status":"superseded","namespace":"payments","release_namespace":"elsewhere","secret":"sh.helm.release.v1.api.v1"}
+{"name":"api","revision":2,"status":"deployed","namespace":"payments","secret":"sh.helm.release.v1.api.v2"}
+{"name":"api","revision":1,"status":"deployed","namespace":"search","secret":"sh.helm.release.v1.api.v1"}
+`
+	assert.Equal(t, expected, out.String())
+	assert.Equal(t, 2, lists)
+}
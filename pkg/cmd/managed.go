@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// gitOpsPrefixes are the prefixes of the labels and annotations GitOps controllers set on the objects they manage
+var gitOpsPrefixes = map[string]string{
+	"argocd.argoproj.io/":          "ArgoCD",
+	"kustomize.toolkit.fluxcd.io/": "Flux",
+	"helm.toolkit.fluxcd.io/":      "Flux",
+}
+
+// managedBy tells which GitOps controller or owner manages the secret, if any
+func managedBy(secret *v1.Secret) string {
+	for _, metadata := range []map[string]string{secret.Labels, secret.Annotations} {
+		for key := range metadata {
+			for prefix, controller := range gitOpsPrefixes {
+				if strings.HasPrefix(key, prefix) {
+					return controller
+				}
+			}
+		}
+	}
+
+	if owner := metav1.GetControllerOf(secret); owner != nil {
+		return fmt.Sprintf("%s %q", owner.Kind, owner.Name)
+	}
+
+	return ""
+}
+
+// warnIfManaged warns that an edit of a secret managed by a controller may be reverted
+func warnIfManaged(secret *v1.Secret) {
+	if controller := managedBy(secret); controller != "" {
+		logrus.Warnf("this release appears to be managed by %s; your edit may be reverted on next sync", controller)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestManagedBy(t *testing.T) {
+	controller := true
+
+	testcases := []struct {
+		name     string
+		meta     metav1.ObjectMeta
+		expected string
+	}{
+		{
+			name:     "unmanaged",
+			meta:     metav1.ObjectMeta{Labels: map[string]string{"owner": "helm"}},
+			expected: "",
+		},
+		{
+			name:     "argocd annotation",
+			meta:     metav1.ObjectMeta{Annotations: map[string]string{"argocd.argoproj.io/tracking-id": "myapp:/Secret:default/mysecret"}},
+			expected: "ArgoCD",
+		},
+		{
+			name:     "flux label",
+			meta:     metav1.ObjectMeta{Labels: map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"}},
+			expected: "Flux",
+		},
+		{
+			name: "controller owner",
+			meta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ConfigMap", Name: "other"},
+				{Kind: "MyOperator", Name: "myapp", Controller: &controller},
+			}},
+			expected: `MyOperator "myapp"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, managedBy(&v1.Secret{ObjectMeta: tc.meta}))
+		})
+	}
+}
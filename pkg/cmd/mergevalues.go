@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	v1 "k8s.io/api/core/v1"
+)
+
+// mergeValues deep merges the values file of --merge-values into the user supplied values of the release,
+// as presented for the edit
+func (o *ModifySecretOptions) mergeValues(content []byte, secret *v1.Secret) ([]byte, error) {
+	warnIfManaged(secret)
+
+	overlay, err := os.ReadFile(o.mergeValuesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := release.FromFormat(o.format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := release.MergeValues(values, overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	return release.ToFormat(o.format, merged)
+}
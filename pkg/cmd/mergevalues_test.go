@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMergeValues(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	valuesFile := filepath.Join(t.TempDir(), "env-prod.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("image:\n  tag: v2\nhosts:\n- prod.example.com\n"), 0644))
+
+	for _, dryRun := range []bool{true, false} {
+		client := fake.NewSimpleClientset(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+			Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp","config":{"image":{"repository":"app","tag":"v1"},"hosts":["a","b"]}}`)},
+		})
+
+		out := &bytes.Buffer{}
+		modify := ModifySecretOptions{
+			IOStreams:       genericclioptions.IOStreams{Out: out},
+			kubeclient:      client,
+			secretName:      "mysecret",
+			namespace:       "mynamespace",
+			mergeValuesFile: valuesFile,
+			dryRun:          dryRun,
+		}
+		require.NoError(t, modify.Run())
+
+		secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+		require.NoError(t, err)
+		if dryRun {
+			assert.Equal(t, "hosts:\n- prod.example.com\nimage:\n  repository: app\n  tag: v2\n", out.String())
+			assert.JSONEq(t, `{"name":"myapp","config":{"image":{"repository":"app","tag":"v1"},"hosts":["a","b"]}}`, decodeRelease(t, secret.Data["release"]))
+			continue
+		}
+		assert.JSONEq(t, `{"name":"myapp","config":{"image":{"repository":"app","tag":"v2"},"hosts":["prod.example.com"]}}`, decodeRelease(t, secret.Data["release"]))
+	}
+}
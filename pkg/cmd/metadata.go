@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// parseKeyValues parses key=value pairs given on the command line
+func parseKeyValues(pairs []string) (map[string]string, error) {
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %q, expected key=value", pair)
+		}
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+// validateLabels ensures the labels are valid Kubernetes labels
+func validateLabels(labels map[string]string) error {
+	for _, k := range sortedKeys(labels) {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", k, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(labels[k]); len(errs) > 0 {
+			return fmt.Errorf("invalid value for label %q: %s", k, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// validateAnnotations ensures the annotation keys are valid Kubernetes annotation keys
+func validateAnnotations(annotations map[string]string) error {
+	for _, k := range sortedKeys(annotations) {
+		if errs := validation.IsQualifiedName(strings.ToLower(k)); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation key %q: %s", k, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// applyMetadata sets the labels and annotations requested on the command line on the secret
+func (o *ModifySecretOptions) applyMetadata(secret *v1.Secret) {
+	if len(o.labels) > 0 && secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	for k, v := range o.labels {
+		secret.Labels[k] = v
+	}
+
+	if len(o.annotations) > 0 && secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	for k, v := range o.annotations {
+		secret.Annotations[k] = v
+	}
+}
+
+// runPatchMetadata sets the labels and annotations requested on the command line on the secret,
+// with a patch which leaves its data and resource version out of the picture
+func (o *ModifySecretOptions) runPatchMetadata() error {
+	if len(o.labels) == 0 && len(o.annotations) == 0 {
+		logrus.Infof("no changes done to secret %q", o.secretName)
+		return nil
+	}
+
+	if o.dryRun {
+		logrus.Infof("labels and annotations of secret %q set (dry run)", o.secretName)
+		return nil
+	}
+
+	err := o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.PatchMetadata(context.TODO(), o.secretName, o.namespace, o.labels, o.annotations, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("labels and annotations of secret %q set", o.secretName)
+	return nil
+}
+
+// sortedKeys returns the keys of the map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
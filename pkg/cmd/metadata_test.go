@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParseAndValidateMetadata(t *testing.T) {
+	testcases := []struct {
+		name        string
+		labels      []string
+		annotations []string
+		err         string
+	}{
+		{
+			name:        "valid labels and annotations",
+			labels:      []string{"team=payments", "example.com/edited=true"},
+			annotations: []string{"edited-by=jane", "edited-reason=INC-1234: rollback image"},
+		},
+		{
+			name:   "missing value",
+			labels: []string{"team"},
+			err:    `invalid "team", expected key=value`,
+		},
+		{
+			name:   "invalid label key",
+			labels: []string{"-team=payments"},
+			err:    `invalid label key "-team"`,
+		},
+		{
+			name:   "invalid label value",
+			labels: []string{"reason=not a label value"},
+			err:    `invalid value for label "reason"`,
+		},
+		{
+			name:        "invalid annotation key",
+			annotations: []string{"edited by=jane"},
+			err:         `invalid annotation key "edited by"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels, err := parseKeyValues(tc.labels)
+			if err == nil {
+				err = validateLabels(labels)
+			}
+
+			annotations, annotationsErr := parseKeyValues(tc.annotations)
+			if err == nil {
+				err = annotationsErr
+			}
+			if err == nil {
+				err = validateAnnotations(annotations)
+			}
+
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestModifySetsMetadata(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "touch")
+
+	secret := releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp"}`)
+	client := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient:  client,
+		secretName:  secret.Name,
+		namespace:   namespace,
+		labels:      map[string]string{"team": "payments"},
+		annotations: map[string]string{"edited-by": "jane"},
+	}
+	require.NoError(t, modify.Run())
+
+	updated, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "payments", updated.Labels["team"])
+	assert.Equal(t, "helm", updated.Labels["owner"])
+	assert.Equal(t, map[string]string{"edited-by": "jane"}, updated.Annotations)
+	assert.Equal(t, secret.Data, updated.Data)
+	assertVerbs(t, client.Actions(), "get", "patch", "get")
+}
+
+// assertVerbs asserts the verbs of the actions done with the fake client
+func assertVerbs(t *testing.T, actions []k8stesting.Action, verbs ...string) {
+	actual := []string{}
+	for _, action := range actions {
+		actual = append(actual, action.GetVerb())
+	}
+	assert.Equal(t, verbs, actual)
+}
+
+func TestModifyEmptyFileCancels(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "sed -i d")
+
+	secret := releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp"}`)
+	client := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: secret.Name,
+		namespace:  namespace,
+		labels:     map[string]string{"team": "payments"},
+	}
+	require.NoError(t, modify.Run())
+
+	updated, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, secret, updated)
+	assertVerbs(t, client.Actions(), "get", "get")
+}
@@ -1,21 +1,27 @@
 package cmd
 
 import (
-	"bytes"
-	"compress/gzip"
+	"bufio"
 	"context"
-	"crypto/md5"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/config"
 	"github.com/rajatjindal/kubectl-modify-secret/pkg/editor"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/modify"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
 	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	//import all supported auth
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -31,16 +37,121 @@ type ModifySecretOptions struct {
 
 	args         []string
 	kubeclient   kubernetes.Interface
+	readClient   kubernetes.Interface
+	storage      string
+	driver       secrets.StorageDriver
 	secretName   string
 	namespace    string
+	fieldManager string
 	printVersion bool
+	checkUpdate  bool
+
+	dryRun          bool
+	batchDir        string
+	continueOnError bool
+	atomic          bool
+	fromFile        string
+	sortKeys        bool
+	mergeTool       string
+	mergeBase       string
+	list            bool
+	fixLabels       bool
+	repair          bool
+	valuesOnly      bool
+	normalize       bool
+	noGzip          bool
+	labelArgs       []string
+	annotationArgs  []string
+	labels          map[string]string
+	annotations     map[string]string
+	literalArgs     []string
+	literals        map[string]string
+	fileArgs        []string
+
+	configPath         string
+	config             *config.Config
+	requireConfirmName bool
+	yes                bool
+	answers            *bufio.Reader
+	history            bool
+	pruneHistory       bool
+	keep               int
+	convertStorage     string
+	deleteSource       bool
+	output             string
+	jsonIndent         int
+	compactJSON        bool
+	format             string
+	namespaceSelector  string
+	fieldSelector      string
+	storageNamespace   string
+	defaultNamespace   string
+	uid                string
+	fromDeployment     string
+	watchCluster       bool
+	sops               bool
+	trimWhitespace     bool
+	semanticCompare    bool
+	forceUpdate        bool
+	hashAlgo           string
+	releaseKey         string
+	includeKeys        []string
+	excludeKeys        []string
+	chartFiles         bool
+	showEncoded        bool
+	diagnose           bool
+	diffDefaults       bool
+	key                string
+	nested             bool
+	chartVersion       string
+	appVersion         string
+	chartSources       []string
+	imageReplacement   string
+	imageInManifest    bool
+	chartFile          string
+	applyTimeout       time.Duration
+	validateAll        bool
+	summary            bool
+	since              time.Duration
+	cachedList         bool
+	fromLabels         bool
+	outputTemplate     string
+	mergeValuesFile    string
+	strict             bool
+	localFile          string
+	localOutput        string
+	allNamespaces      bool
+	helmExport         string
+	notesOnly          bool
+	logLevel           string
+	verbose            bool
+	newRevision        bool
+	verify             bool
+	pickContext        bool
+	diffContext        int
+	showEncodedDiff    bool
+	editFIFO           string
+	editStdio          bool
+	transform          string
+	keepTempfile       bool
+	noComments         bool
+	showComputed       bool
+	allowPartial       bool
+	printSecretName    bool
+	readServer         string
+	preserveFields     bool
+	revision           int
+	wait               bool
+	forStatus          string
+	waitTimeout        time.Duration
 }
 
 // NewModifySecretOptions provides an instance of ModifySecretOptions with default values
 func NewModifySecretOptions(streams genericclioptions.IOStreams) *ModifySecretOptions {
 	return &ModifySecretOptions{
-		configFlags: genericclioptions.NewConfigFlags(true),
-		IOStreams:   streams,
+		configFlags:      genericclioptions.NewConfigFlags(true),
+		IOStreams:        streams,
+		defaultNamespace: metav1.NamespaceDefault,
 	}
 }
 
@@ -51,6 +162,7 @@ func NewCmdModifySecret(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:          "modify-secret [secret-name] [flags]",
 		Short:        "Modify the secret with implicit base64 translations",
+		Long:         "Modify the secret with implicit base64 translations\n\n" + exitCodesHelp,
 		SilenceUsage: true,
 		RunE: func(c *cobra.Command, args []string) error {
 			if o.printVersion {
@@ -58,21 +170,122 @@ func NewCmdModifySecret(streams genericclioptions.IOStreams) *cobra.Command {
 				os.Exit(0)
 			}
 
+			if o.checkUpdate {
+				return checkUpdate(o.IOStreams.Out, latestReleaseURL)
+			}
+
 			if err := o.Complete(c, args); err != nil {
-				return err
+				return newExitError(err)
 			}
 			if err := o.Validate(); err != nil {
-				return err
+				return newExitError(err)
 			}
 			if err := o.Run(); err != nil {
-				return err
+				return newExitError(err)
 			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.pickContext, "pick-context", false, "when no --context is given, pick the kubeconfig context from a menu if stdin is a terminal")
+	cmd.Flags().StringVar(&o.logLevel, "log-level", "info", "log level, one of debug, info, warn or error")
+	cmd.Flags().BoolVarP(&o.verbose, "verbose", "v", false, "trace each decoding and encoding step of the release with its size, the same as --log-level debug")
 	cmd.Flags().BoolVar(&o.printVersion, "version", false, "prints version of plugin")
+	cmd.Flags().BoolVar(&o.checkUpdate, "check-update", false, "checks whether a newer version of plugin is available")
+	cmd.Flags().StringVar(&o.localFile, "local-file", "", "work offline on the manifest of a secret or configmap saved with kubectl get -o yaml, without connecting to a cluster; the updated manifest is written back to the file")
+	cmd.Flags().StringVar(&o.localOutput, "local-output", "", "with --local-file, file the updated manifest is written to instead, - for stdout")
+	cmd.Flags().StringVar(&o.storage, "storage", secrets.StorageSecret, "storage Helm keeps releases in, secret or configmap")
+	cmd.Flags().StringVar(&o.readServer, "read-server", "", "address of the API server releases are read from, such as a read replica; updates still go to the server of the kubeconfig, which reads default to")
+	cmd.Flags().StringVar(&o.uid, "uid", "", "select the secret by its UID instead of its name")
+	cmd.Flags().StringVar(&o.fromDeployment, "from-deployment", "", "select the secret among the ones referenced by the env and volumes of the deployment, from a menu when there are several")
+	cmd.Flags().StringVar(&o.defaultNamespace, "default-namespace", metav1.NamespaceDefault, "namespace used when none is given with --namespace or set in the kubeconfig context; empty makes it an error")
+	cmd.Flags().StringVar(&o.storageNamespace, "storage-namespace", "", "namespace Helm stores the release in, when it differs from the namespace its resources are deployed to; defaults to --namespace")
+	cmd.Flags().BoolVar(&o.wait, "wait", false, "after the edit, wait until the release stored in the secret has the status of --for-status, for instance once a controller post-processed it")
+	cmd.Flags().StringVar(&o.forStatus, "for-status", "", "with --wait, status of the release to wait for, e.g. deployed")
+	cmd.Flags().DurationVar(&o.waitTimeout, "timeout", time.Minute, "with --wait, how long to wait for the status")
+	cmd.Flags().DurationVar(&o.applyTimeout, "apply-timeout", 15*time.Second, "timeout of the update of the secret, which starts when the editor is closed; 0 means no timeout")
+	cmd.Flags().StringVar(&o.fieldManager, "field-manager", secrets.DefaultFieldManager, "name of the manager used to track field ownership")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "show what would be changed without updating the secret")
+	cmd.Flags().IntVar(&o.diffContext, "diff-context", 3, "number of context lines around each change in the diff printed by --dry-run, like diff -U")
+	cmd.Flags().BoolVar(&o.showEncodedDiff, "show-encoded-diff", false, "with --dry-run, also print the size and the base64 and gzip layers of the release as stored, before and after the edit")
+	cmd.Flags().BoolVar(&o.list, "list", false, "list the Helm releases stored in the namespace")
+	cmd.Flags().BoolVar(&o.validateAll, "validate-all", false, "check that every Helm release in the namespace decodes, and report the corrupt ones")
+	cmd.Flags().BoolVar(&o.summary, "summary", false, "count the revisions of the Helm releases in the namespace by status and by release, flagging the releases to prune")
+	cmd.Flags().DurationVar(&o.since, "since", 0, "with --list or --summary, only show the revisions last deployed within this duration, e.g. 1h")
+	cmd.Flags().BoolVar(&o.fromLabels, "from-labels", false, "with --list or --summary, read the name, version and status of the revisions from the name, version and status labels Helm sets on their secret instead of decoding them; faster, but trusts labels which may be stale, see --fix-labels")
+	cmd.Flags().BoolVar(&o.cachedList, "cached-list", false, "with --list, --validate-all or --summary, read the releases from the watch cache of the API server, like informers do, instead of etcd; lighter on clusters with many releases, but possibly slightly stale")
+	cmd.Flags().StringVar(&o.outputTemplate, "output-template", "", "with --list, Go template printed for each release instead of the table, e.g. '{{.Name}} {{.Status}}'; the template is given the decoded release")
+	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", false, "with --list, --validate-all or --summary, operate in all the namespaces")
+	cmd.Flags().BoolVar(&o.history, "history", false, "list the revisions of the release given as argument, like helm history")
+	cmd.Flags().BoolVar(&o.pruneHistory, "prune-history", false, "delete the revisions of the release given as argument but the most recent ones, never the deployed one, like helm upgrade --history-max")
+	cmd.Flags().IntVar(&o.keep, "keep", 10, "number of most recent revisions kept by --prune-history")
+	cmd.Flags().StringVar(&o.convertStorage, "convert-storage", "", "copy the revisions of the release given as argument from --storage to this storage, secret or configmap, to migrate Helm's storage backend")
+	cmd.Flags().BoolVar(&o.deleteSource, "delete-source", false, "with --convert-storage, delete the original revisions once they are all copied")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "output format of --history or --diagnose, either empty for text or json; --list and --summary also support jsonl, one JSON object per release and line, and --list supports wide, adding the chart and app version")
+	cmd.Flags().IntVar(&o.jsonIndent, "json-indent", 2, "number of spaces JSON output is indented with; 0 prints it on a single line")
+	cmd.Flags().BoolVar(&o.compactJSON, "compact-json", false, "print JSON output on a single line, for jq pipelines")
+	cmd.Flags().BoolVar(&o.fixLabels, "fix-labels", false, "recompute the status, version and modifiedAt labels of the secret from its release")
+	cmd.Flags().BoolVar(&o.repair, "repair", false, "strip the extra base64 and gzip layers a release was wrapped in by mistake and store it the way Helm does")
+	cmd.Flags().StringVar(&o.namespaceSelector, "namespace-selector", "", "with --list or --batch, operate in the namespaces matching this label selector")
+	cmd.Flags().StringVar(&o.fieldSelector, "field-selector", "", "with --list, --validate-all or --history, field selector the API server filters the releases with, e.g. type=helm.sh/release.v1")
+	cmd.Flags().StringVar(&o.batchDir, "batch", "", "directory of merge patch files, each named after the release it applies to")
+	cmd.Flags().StringVar(&o.format, "format", release.FormatYAML, "format of the release in the editor, either yaml or json")
+	cmd.Flags().BoolVar(&o.valuesOnly, "values-only", false, "edit only the user supplied values of the release, validated against the chart schema when it has one")
+	cmd.Flags().StringVar(&o.mergeValuesFile, "merge-values", "", "YAML or JSON values file deep merged into the user supplied values of the release without opening an editor; maps are merged, arrays and other values replaced")
+	cmd.Flags().BoolVar(&o.notesOnly, "notes-only", false, "edit only the rendered NOTES.txt of the release, as plain text")
+	cmd.Flags().BoolVar(&o.diagnose, "diagnose", false, "print a read-only report on the secret: its type and labels, the encoding layers and decoded size of each key, whether the release decodes, and the anomalies found; -o json prints it as JSON")
+	cmd.Flags().BoolVar(&o.showEncoded, "show-encoded", false, "print the size, encoding layers and a preview of each key of the secret, as stored and as decoded")
+	cmd.Flags().BoolVar(&o.printSecretName, "print-secret-name", false, "print the name of the secret storing the release given as argument, at its latest revision or the one of --revision, and exit")
+	cmd.Flags().IntVar(&o.revision, "revision", 0, "with --print-secret-name, revision of the release; the latest when not set")
+	cmd.Flags().BoolVar(&o.allowPartial, "allow-partial", false, "print the decoded release without editing it; when its gzip stream is truncated or corrupt, print the content decompressed before the error, to recover what can be")
+	cmd.Flags().BoolVar(&o.diffDefaults, "diff-defaults", false, "print the YAML diff between the default values of the chart and the values the release is deployed with")
+	cmd.Flags().StringVar(&o.key, "key", "", "with --nested, key of the secret holding the YAML document to edit")
+	cmd.Flags().BoolVar(&o.nested, "nested", false, "edit the YAML document held by the key given with --key, decoded, instead of the release; it must still parse as YAML to be saved")
+	cmd.Flags().StringVar(&o.chartVersion, "set-chart-version", "", "set the chart version recorded in the release, e.g. to correct a mislabeled chart, without opening an editor")
+	cmd.Flags().StringVar(&o.appVersion, "set-app-version", "", "set the app version recorded in the chart of the release, without opening an editor")
+	cmd.Flags().StringVar(&o.imageReplacement, "replace-image", "", "replace an image reference in the values of the release without opening an editor, as OLD=NEW, e.g. myrepo/app:v1=myrepo/app:v2; repository and tag values are updated too")
+	cmd.Flags().BoolVar(&o.imageInManifest, "replace-image-manifest", false, "with --replace-image, also replace the image in the rendered manifest of the release")
+	cmd.Flags().StringArrayVar(&o.chartSources, "set-chart-source", nil, "replace the sources recorded in the chart metadata of the release, e.g. its OCI reference after a registry migration, without opening an editor (repeatable)")
+	cmd.Flags().BoolVar(&o.chartFiles, "chart-files", false, "list the files packaged in the chart of the release")
+	cmd.Flags().StringVar(&o.chartFile, "chart-file", "", "edit the named file packaged in the chart of the release, decoded")
+	cmd.Flags().BoolVar(&o.normalize, "normalize", false, "store the release JSON in a canonical form, with sorted keys, so identical releases are stored as identical bytes")
+	cmd.Flags().BoolVar(&o.noGzip, "no-gzip", false, "store the release uncompressed, which increases its size")
+	cmd.Flags().StringArrayVar(&o.literalArgs, "from-literal", nil, "set a key of the secret to a literal value without opening an editor, as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&o.fileArgs, "from-file", nil, "set a key of the secret to the content of a file without opening an editor, as key=path or path to use the file name as key (repeatable)")
+	cmd.Flags().BoolVar(&o.requireConfirmName, "require-confirm-name", false, "require typing the secret name to confirm the edit")
+	cmd.Flags().BoolVar(&o.yes, "yes", false, "clear keys with --from-literal or --from-file without asking to confirm")
+	cmd.Flags().StringVar(&o.configPath, "config", config.DefaultPath(), "path of the plugin configuration file")
+	cmd.Flags().StringArrayVar(&o.labelArgs, "set-label", nil, "label to set on the secret along with the edit, as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&o.annotationArgs, "set-annotation", nil, "annotation to set on the secret along with the edit, as key=value (repeatable)")
+	cmd.Flags().StringVar(&o.helmExport, "from-helm-export", "", "file holding the output of helm get all, merged into the release instead of opening the editor")
+	cmd.Flags().BoolVar(&o.verify, "verify", false, "get the secret again after the update and fail if the stored release differs from the edit, e.g. changed by a mutating admission webhook")
+	cmd.Flags().BoolVar(&o.newRevision, "new-revision", false, "store the edit as a new deployed revision of the release and mark the one deployed until then superseded, like helm upgrade")
+	cmd.Flags().BoolVar(&o.sortKeys, "sort-keys", true, "present the release with its keys in sorted order, so diffs between edits are stable")
+	cmd.Flags().StringVar(&o.fromFile, "from", "", "use the content of this file as the edited release instead of opening an editor")
+	cmd.Flags().StringVar(&o.fromFile, "replace-from", "", "same as --from: replace the whole decoded release with the content of this file")
+	cmd.MarkFlagsMutuallyExclusive("from", "replace-from")
+	cmd.Flags().BoolVar(&o.watchCluster, "watch-cluster", false, "watch the secret while editing and ask what to do if it changes on the server meanwhile")
+	cmd.Flags().BoolVar(&o.preserveFields, "preserve-server-fields", false, "read the secret again before updating it and only change its data and the labels and annotations set, keeping the annotations or finalizers added on the server while editing")
+	cmd.Flags().BoolVar(&o.noComments, "no-comments", false, "don't prepend guidance comments to the release in the editor")
+	cmd.Flags().BoolVar(&o.showComputed, "show-computed", false, "show the chart defaults merged with the values of the release in the comments of the editor, for reference")
+	cmd.Flags().BoolVar(&o.keepTempfile, "keep-tempfile", false, "keep the temporary file holding the edited release, in plain text, and print its path on exit")
+	cmd.Flags().StringVar(&o.releaseKey, "release-key", modify.DefaultReleaseKey, "key of the secret holding the release; when missing, the only base64+gzip key of the secret is edited")
+	cmd.Flags().StringSliceVar(&o.includeKeys, "include-keys", nil, "comma separated keys of the secret which may be decoded, to find the release or with --show-encoded; the others are kept verbatim")
+	cmd.Flags().StringSliceVar(&o.excludeKeys, "exclude-keys", nil, "comma separated keys of the secret never decoded, such as huge binary values; they are kept verbatim")
+	cmd.Flags().StringVar(&o.hashAlgo, "hash-algo", modify.HashSHA256, "hash algorithm detecting whether the release was edited, one of md5, sha1 or sha256")
+	cmd.Flags().BoolVar(&o.strict, "strict", false, "reject an edit adding fields Helm doesn't know to the release, such as a misspelled info.stauts Helm would silently drop")
+	cmd.Flags().BoolVar(&o.trimWhitespace, "trim-whitespace", true, "strip trailing whitespace and normalize the final newline of the edited release before comparing and saving it")
+	cmd.Flags().BoolVar(&o.semanticCompare, "semantic-nochange-detection", false, "treat an edit whose release parses to the same data as before, such as reordered keys or requoted strings, as no change")
+	cmd.Flags().BoolVar(&o.forceUpdate, "force-update", false, "encode the release again and update the secret even if the edit changed nothing, with --normalize or --no-gzip to store it differently")
+	cmd.Flags().BoolVar(&o.sops, "sops", false, "edit SOPS encrypted content decrypted, through the sops binary which re-encrypts it on save")
+	cmd.Flags().StringVar(&o.editFIFO, "edit-fifo", "", "named pipe the release is written to and read back from once edited, instead of a temporary file and the editor")
+	cmd.Flags().StringVar(&o.transform, "transform", "", "program the release is piped to instead of the editor, e.g. ./script.sh; what it prints is applied, and the secret is left untouched when it fails")
+	cmd.Flags().BoolVar(&o.editStdio, "edit-stdio", false, "write the release to stdout and read it back from stdin once edited, instead of a temporary file and the editor")
+	cmd.Flags().StringVar(&o.mergeTool, "merge-tool", "", "merge tool (e.g. vimdiff, meld) to use instead of the editor")
+	cmd.Flags().StringVar(&o.mergeBase, "merge-base", "", "file the release is reconciled with in the merge tool")
+	cmd.Flags().BoolVar(&o.continueOnError, "continue-on-error", false, "in batch mode, keep applying patches after a failure")
+	cmd.Flags().BoolVar(&o.atomic, "atomic", false, "with a secret name pattern, edit all the matching secrets or none, restoring the ones already updated when an update fails")
 	o.configFlags.AddFlags(cmd.Flags())
 
 	return cmd
@@ -86,23 +299,348 @@ func (o *ModifySecretOptions) Complete(cmd *cobra.Command, args []string) error
 		o.secretName = args[0]
 	}
 
-	config, err := o.configFlags.ToRESTConfig()
+	err := validateNotEmpty(cmd, "set-chart-version", "set-app-version")
+	if err != nil {
+		return err
+	}
+
+	if o.logLevel != "" {
+		level, err := logrus.ParseLevel(o.logLevel)
+		if err != nil {
+			return err
+		}
+		logrus.SetLevel(level)
+	}
+	if o.verbose {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+
+	o.labels, err = parseKeyValues(o.labelArgs)
+	if err != nil {
+		return err
+	}
+
+	o.annotations, err = parseKeyValues(o.annotationArgs)
+	if err != nil {
+		return err
+	}
+
+	o.literals, err = parseKeyValues(o.literalArgs)
 	if err != nil {
 		return err
 	}
 
-	o.kubeclient, err = kubernetes.NewForConfig(config)
+	if o.localFile != "" {
+		err = o.completeLocal()
+	} else {
+		err = o.completeCluster()
+	}
 	if err != nil {
 		return err
 	}
 
-	o.namespace = getNamespace(o.configFlags)
+	o.config, err = config.Load(o.configPath)
+	if err != nil {
+		return fmt.Errorf("invalid configuration file %s: %v", o.configPath, err)
+	}
+
+	return nil
+}
+
+// completeCluster connects to the cluster and resolves the namespace to operate in
+func (o *ModifySecretOptions) completeCluster() error {
+	err := o.pickContextIfNeeded()
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.kubeclient, err = kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	if o.readServer != "" {
+		readConfig := rest.CopyConfig(restConfig)
+		readConfig.Host = o.readServer
+		o.readClient, err = kubernetes.NewForConfig(readConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.driver, err = o.newDriver()
+	if err != nil {
+		return err
+	}
+
+	o.namespace, err = getNamespace(o.configFlags, o.defaultNamespace)
+	if err != nil {
+		return err
+	}
+	if o.storageNamespace != "" {
+		// the release is read from and written to the namespace Helm stores it in, not the one its resources are deployed to
+		o.namespace = o.storageNamespace
+	}
+
+	return nil
+}
+
+// completeLocal works on the manifest of --local-file, without any connection to a cluster.
+// The secret name and namespace default to the ones of the manifest.
+func (o *ModifySecretOptions) completeLocal() error {
+	driver := &secrets.FileDriver{Path: o.localFile, OutPath: o.localOutput}
+	if o.localOutput == "-" {
+		driver.OutPath = ""
+		driver.Out = o.IOStreams.Out
+	}
+
+	secret, err := driver.Read()
+	if err != nil {
+		return err
+	}
+
+	if o.secretName == "" {
+		o.secretName = secret.Name
+	}
+	o.namespace = secret.Namespace
+	o.driver = driver
+
 	return nil
 }
 
 // Validate ensures that all required arguments and flag values are provided
 func (o *ModifySecretOptions) Validate() error {
-	if len(o.args) == 0 {
+	if err := validateLabels(o.labels); err != nil {
+		return err
+	}
+
+	if err := validateAnnotations(o.annotations); err != nil {
+		return err
+	}
+
+	if isNamePattern(o.secretName) {
+		if _, err := filepath.Match(o.secretName, ""); err != nil {
+			return fmt.Errorf("invalid secret name pattern %q: %v", o.secretName, err)
+		}
+		if len(o.literals) == 0 && len(o.fileArgs) == 0 {
+			return fmt.Errorf("a secret name pattern is only supported with --from-literal or --from-file, editing several secrets at once is ambiguous")
+		}
+	}
+
+	if o.revision < 0 {
+		return fmt.Errorf("--revision must not be negative")
+	}
+
+	if o.revision > 0 && !o.printSecretName {
+		return fmt.Errorf("--revision requires --print-secret-name")
+	}
+
+	if o.atomic && !isNamePattern(o.secretName) {
+		return fmt.Errorf("--atomic requires a secret name pattern")
+	}
+
+	if o.localOutput != "" && o.localFile == "" {
+		return fmt.Errorf("--local-output requires --local-file")
+	}
+
+	if o.localFile != "" && (o.uid != "" || o.batchDir != "" || o.list || o.validateAll || o.summary || o.namespaceSelector != "" || o.allNamespaces || o.watchCluster || o.newRevision || o.pruneHistory || o.convertStorage != "" || o.pickContext || o.fromDeployment != "" || o.readServer != "") {
+		return fmt.Errorf("--local-file cannot be used with --uid, --batch, --list, --validate-all, --summary, --namespace-selector, --all-namespaces, --watch-cluster, --new-revision, --prune-history, --convert-storage, --pick-context, --from-deployment or --read-server")
+	}
+
+	if o.allNamespaces && o.namespaceSelector != "" {
+		return fmt.Errorf("--all-namespaces and --namespace-selector cannot be used together")
+	}
+
+	if o.since < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
+
+	if o.since > 0 && !o.list && !o.summary {
+		return fmt.Errorf("--since requires --list or --summary")
+	}
+
+	if o.wait != (o.forStatus != "") {
+		return fmt.Errorf("--wait and --for-status must be used together")
+	}
+
+	if o.wait && o.dryRun {
+		return fmt.Errorf("--wait cannot be used with --dry-run, nothing is updated")
+	}
+
+	if o.wait && o.waitTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	if o.fromLabels && !o.list && !o.summary {
+		return fmt.Errorf("--from-labels requires --list or --summary")
+	}
+
+	if o.fromLabels && (o.since > 0 || o.output == "wide" || o.outputTemplate != "") {
+		return fmt.Errorf("--from-labels cannot be used with --since, -o wide or --output-template, they need the decoded release")
+	}
+
+	if o.outputTemplate != "" && !o.list {
+		return fmt.Errorf("--output-template requires --list")
+	}
+
+	if o.output != "" && o.output != "json" && o.output != "jsonl" && o.output != "wide" {
+		return fmt.Errorf("unsupported output format %q", o.output)
+	}
+
+	if o.output == "wide" && (!o.list || o.outputTemplate != "") {
+		return fmt.Errorf("-o wide is only supported with --list, without --output-template")
+	}
+
+	if o.output == "jsonl" && (!o.list && !o.summary || o.outputTemplate != "") {
+		return fmt.Errorf("-o jsonl is only supported with --list or --summary, without --output-template")
+	}
+
+	if o.batchDir != "" || o.list || o.validateAll || o.summary {
+		if len(o.args) > 0 {
+			return fmt.Errorf("no argument is allowed with --batch, --list, --validate-all or --summary")
+		}
+		return nil
+	}
+
+	if o.mergeBase != "" && o.mergeTool == "" {
+		return fmt.Errorf("--merge-base requires --merge-tool")
+	}
+
+	if o.mergeTool != "" && o.fromFile != "" {
+		return fmt.Errorf("--merge-tool and --from cannot be used together")
+	}
+
+	if o.format != release.FormatYAML && o.format != release.FormatJSON {
+		return fmt.Errorf("unsupported format %q", o.format)
+	}
+
+	if o.helmExport != "" && (o.fromFile != "" || o.mergeTool != "" || o.valuesOnly || o.chartFile != "" || o.notesOnly) {
+		return fmt.Errorf("--from-helm-export cannot be used with --from, --merge-tool, --values-only, --chart-file or --notes-only")
+	}
+
+	if o.editFIFO != "" || o.editStdio {
+		if o.editFIFO != "" && o.editStdio {
+			return fmt.Errorf("--edit-fifo and --edit-stdio cannot be used together")
+		}
+		if o.fromFile != "" || o.mergeTool != "" || o.helmExport != "" || o.sops || o.watchCluster {
+			return fmt.Errorf("--edit-fifo and --edit-stdio cannot be used with --from, --merge-tool, --from-helm-export, --sops or --watch-cluster")
+		}
+		if o.editStdio && !o.dryRun && o.requiresConfirmName() {
+			return fmt.Errorf("--edit-stdio cannot be used when the secret name must be confirmed, stdin holds the edited release; use --edit-fifo instead")
+		}
+	}
+
+	if o.showComputed && (o.noComments || o.chartFile != "" || o.notesOnly) {
+		return fmt.Errorf("--show-computed cannot be used with --no-comments, --chart-file or --notes-only, the computed values are shown in the comments")
+	}
+
+	if o.transform != "" && (o.editFIFO != "" || o.editStdio || o.fromFile != "" || o.mergeTool != "" || o.mergeValuesFile != "" || o.helmExport != "" || o.sops || o.watchCluster || o.nested) {
+		return fmt.Errorf("--transform cannot be used with --edit-fifo, --edit-stdio, --from, --merge-tool, --merge-values, --from-helm-export, --sops, --watch-cluster or --nested")
+	}
+
+	if o.nested != (o.key != "") {
+		return fmt.Errorf("--nested and --key must be used together")
+	}
+
+	if o.nested && (o.valuesOnly || o.chartFile != "" || o.notesOnly || o.helmExport != "" || o.watchCluster || o.newRevision) {
+		return fmt.Errorf("--nested cannot be used with --values-only, --chart-file, --notes-only, --from-helm-export, --watch-cluster or --new-revision")
+	}
+
+	for _, source := range o.chartSources {
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf("--set-chart-source must not be empty")
+		}
+	}
+
+	if o.setsChartMetadata() && (o.nested || o.fromFile != "" || len(o.literals) > 0 || len(o.fileArgs) > 0 || o.newRevision) {
+		return fmt.Errorf("--set-chart-version, --set-app-version and --set-chart-source cannot be used with --nested, --from, --from-literal, --from-file or --new-revision")
+	}
+
+	if o.imageReplacement != "" {
+		if _, _, err := parseImageReplacement(o.imageReplacement); err != nil {
+			return err
+		}
+		if o.setsChartMetadata() || o.nested || o.fromFile != "" || len(o.literals) > 0 || len(o.fileArgs) > 0 || o.newRevision {
+			return fmt.Errorf("--replace-image cannot be used with --set-chart-version, --set-app-version, --set-chart-source, --nested, --from, --from-literal, --from-file or --new-revision")
+		}
+	}
+
+	if o.imageInManifest && o.imageReplacement == "" {
+		return fmt.Errorf("--replace-image-manifest requires --replace-image")
+	}
+
+	if o.mergeValuesFile != "" && (o.fromFile != "" || o.mergeTool != "" || o.helmExport != "" || o.chartFile != "" || o.notesOnly || o.editFIFO != "" || o.editStdio || o.nested) {
+		return fmt.Errorf("--merge-values cannot be used with --from, --merge-tool, --from-helm-export, --chart-file, --notes-only, --edit-fifo, --edit-stdio or --nested")
+	}
+
+	if countTrue(o.valuesOnly, o.chartFile != "", o.notesOnly) > 1 {
+		return fmt.Errorf("only one of --values-only, --chart-file and --notes-only can be used")
+	}
+
+	if o.watchCluster && o.storage == secrets.StorageConfigMap {
+		return fmt.Errorf("--watch-cluster is only supported with --storage %s", secrets.StorageSecret)
+	}
+
+	if o.showEncodedDiff && !o.dryRun {
+		return fmt.Errorf("--show-encoded-diff requires --dry-run")
+	}
+
+	if o.diffContext < 0 {
+		return fmt.Errorf("--diff-context must not be negative")
+	}
+
+	if o.hashAlgo != "" && o.hashAlgo != modify.HashMD5 && o.hashAlgo != modify.HashSHA1 && o.hashAlgo != modify.HashSHA256 {
+		return fmt.Errorf("unsupported hash algorithm %q", o.hashAlgo)
+	}
+
+	if o.verify && o.newRevision {
+		return fmt.Errorf("--verify cannot be used with --new-revision")
+	}
+
+	if o.convertStorage != "" && o.convertStorage != secrets.StorageSecret && o.convertStorage != secrets.StorageConfigMap {
+		return fmt.Errorf("unsupported storage %q for --convert-storage, use %s or %s", o.convertStorage, secrets.StorageSecret, secrets.StorageConfigMap)
+	}
+
+	if o.convertStorage != "" && (o.convertStorage == o.storage || o.storage == "" && o.convertStorage == secrets.StorageSecret) {
+		return fmt.Errorf("the release is already stored in a %s, --convert-storage must differ from --storage", o.convertStorage)
+	}
+
+	if o.deleteSource && o.convertStorage == "" {
+		return fmt.Errorf("--delete-source requires --convert-storage")
+	}
+
+	if o.pruneHistory && o.keep < 1 {
+		return fmt.Errorf("--keep must be at least 1, the deployed revision is always kept")
+	}
+
+	if o.jsonIndent < 0 {
+		return fmt.Errorf("--json-indent must not be negative")
+	}
+
+	if o.uid != "" && o.fromDeployment != "" {
+		return fmt.Errorf("--uid and --from-deployment cannot be used together")
+	}
+
+	if o.uid != "" {
+		if len(o.args) > 0 {
+			return fmt.Errorf("no argument is allowed with --uid")
+		}
+		return nil
+	}
+
+	if o.fromDeployment != "" {
+		if len(o.args) > 0 {
+			return fmt.Errorf("no argument is allowed with --from-deployment")
+		}
+		return nil
+	}
+
+	if len(o.args) == 0 && o.localFile == "" {
 		return fmt.Errorf("atleast one argument is required")
 	}
 
@@ -115,106 +653,417 @@ func (o *ModifySecretOptions) Validate() error {
 
 // Run fetches the given secret manifest from the cluster, decodes the payload, opens an editor to make changes, and applies the modified manifest when done
 func (o *ModifySecretOptions) Run() error {
-	secret, err := secrets.Get(context.TODO(), o.kubeclient, o.secretName, o.namespace)
-	if err != nil {
-		return err
+	if o.driver == nil {
+		var err error
+		o.driver, err = o.newDriver()
+		if err != nil {
+			return err
+		}
 	}
 
-	data := make(map[string]string, len(secret.Data))
-	for k, v := range secret.Data {
-		decodedSecretLevel1, err := base64.StdEncoding.DecodeString(string(v))
+	if o.uid != "" {
+		var err error
+		o.secretName, err = o.secretNameByUID()
 		if err != nil {
-			return fmt.Errorf("erreur lors du premier décodage base64 : %v", err)
+			return err
 		}
-		r, err := gzip.NewReader(bytes.NewReader(decodedSecretLevel1))
+	}
+
+	if o.fromDeployment != "" {
+		var err error
+		o.secretName, err = o.secretNameFromDeployment()
 		if err != nil {
-			return fmt.Errorf("erreur lors de la création du lecteur gzip : %v", err)
+			return err
 		}
-		defer r.Close()
+	}
+
+	if o.batchDir != "" {
+		return o.runBatch()
+	}
+
+	if o.list {
+		return o.runList()
+	}
+
+	if o.validateAll {
+		return o.runValidateAll()
+	}
+
+	if o.summary {
+		return o.runSummary()
+	}
+
+	if o.fixLabels {
+		return o.runFixLabels()
+	}
+
+	if o.repair {
+		return o.runRepair()
+	}
+
+	if o.history {
+		return o.runHistory()
+	}
+
+	if o.pruneHistory {
+		return o.runPruneHistory()
+	}
+
+	if o.convertStorage != "" {
+		return o.runConvertStorage()
+	}
+
+	if o.chartFiles {
+		return o.runChartFiles()
+	}
+
+	if o.printSecretName {
+		return o.runPrintSecretName()
+	}
+
+	if o.allowPartial {
+		return o.runRecoverPartial()
+	}
+
+	if o.showEncoded {
+		return o.runShowEncoded()
+	}
 
-		decompressedSecret, err := ioutil.ReadAll(r)
+	if o.diagnose {
+		return o.runDiagnose()
+	}
+
+	if o.diffDefaults {
+		return o.runDiffDefaults()
+	}
+
+	if o.nested {
+		return o.runEditNested()
+	}
+
+	if o.imageReplacement != "" {
+		return o.runReplaceImage()
+	}
+
+	if o.setsChartMetadata() {
+		return o.runSetChartMetadata()
+	}
+
+	if len(o.literals) > 0 || len(o.fileArgs) > 0 {
+		values, err := o.keyValues()
 		if err != nil {
-			return fmt.Errorf("erreur lors de la décompression gzip : %v", err)
+			return err
 		}
-		data[k] = string(decompressedSecret)
-
+		if isNamePattern(o.secretName) {
+			return o.runSetKeysMatching(values)
+		}
+		return o.runSetKeys(values)
 	}
 
-	tempfile, err := os.CreateTemp("", fmt.Sprintf("%s-%s-*.yaml", o.namespace, o.secretName))
+	result, err := modify.Run(context.TODO(), o.kubeclient, o.modifyOptions())
+	if result.File != "" {
+		defer logrus.Warnf("the edited release was kept in %s, in plain text: delete it once done", result.File)
+	}
+	if errors.Is(err, modify.ErrEmptyEdit) {
+		logrus.Infof("%v, secret %q left untouched", err, o.secretName)
+		return nil
+	}
+	var truncated *release.TruncatedError
+	if errors.As(err, &truncated) {
+		logrus.Infof("use --allow-partial to print the %d bytes decompressed before the error", len(truncated.Partial))
+	}
 	if err != nil {
+		if result.Changed {
+			o.saveEdits(result.After)
+		}
 		return err
 	}
-	defer os.Remove(tempfile.Name())
 
-	release, ok := data["release"]
-	if !ok {
-		return fmt.Errorf("no .release")
+	if !result.Changed && !o.forceUpdate {
+		return o.runPatchMetadata()
 	}
-	err = os.WriteFile(tempfile.Name(), []byte(release), 0644)
-	if err != nil {
-		return err
+
+	if o.dryRun {
+		logrus.Infof("secret %q edited (dry run)", o.secretName)
+		if o.mergeValuesFile != "" {
+			// the resulting values are more telling than the diff of a merge
+			_, err = o.IOStreams.Out.Write(result.After)
+		} else {
+			err = o.printDiff(result.Before, result.After)
+		}
+		if err != nil {
+			return err
+		}
+		if o.showEncodedDiff {
+			o.printEncodedDiff(result.EncodedBefore, result.EncodedAfter)
+		}
+		return nil
+	}
+
+	if isRecoveryFile(o.fromFile) {
+		os.Remove(o.fromFile)
+	}
+
+	if o.newRevision {
+		logrus.Infof("edit of secret %q stored as the new revision %q", o.secretName, result.Secret.Name)
+	} else {
+		logrus.Infof("secret %q edited", o.secretName)
+	}
+
+	if o.wait {
+		return o.waitForStatus(result.Secret.Name)
+	}
+
+	return nil
+}
+
+// modifyOptions returns the options of the edit of the release
+func (o *ModifySecretOptions) modifyOptions() modify.Options {
+	opts := modify.Options{
+		Name:            o.secretName,
+		Namespace:       o.namespace,
+		Driver:          o.driver,
+		Edit:            o.edit,
+		Format:          o.format,
+		SortKeys:        o.sortKeys,
+		ValuesOnly:      o.valuesOnly,
+		ChartFile:       o.chartFile,
+		NotesOnly:       o.notesOnly,
+		NoGzip:          o.noGzip,
+		Normalize:       o.normalize,
+		TrimWhitespace:  o.trimWhitespace,
+		SemanticCompare: o.semanticCompare,
+		ApplyUnchanged:  o.forceUpdate,
+		Strict:          o.strict,
+		HashAlgo:        o.hashAlgo,
+		ReleaseKey:      o.releaseKey,
+		IncludeKeys:     o.includeKeys,
+		ExcludeKeys:     o.excludeKeys,
+		RemoveOnSignal:  true,
+		NoComments:      o.noComments,
+		ShowComputed:    o.showComputed,
+		KeepFile:        o.keepTempfile,
+		FieldManager:    o.fieldManager,
+		ApplyTimeout:    o.applyTimeout,
+		DryRun:          o.dryRun,
+		NewRevision:     o.newRevision,
+		Verify:          o.verify,
+		PreserveFields:  o.preserveFields,
+		BeforeUpdate: func(secret *v1.Secret) error {
+			o.applyMetadata(secret)
+			return o.confirmName()
+		},
+	}
+
+	if o.editFIFO != "" || o.editStdio {
+		opts.EditContent = o.editContent
 	}
 
-	originalSum := md5.Sum([]byte(release))
+	if o.transform != "" {
+		opts.EditContent = o.transformContent
+	}
+
+	if o.mergeValuesFile != "" {
+		opts.ValuesOnly = true
+		opts.EditContent = o.mergeValues
+	}
+
+	return opts
+}
 
-	err = editor.Edit(tempfile.Name())
+// edit lets the user edit the release with the editor, the merge tool, sops or the content of --from
+func (o *ModifySecretOptions) edit(file string, secret *v1.Secret) error {
+	warnIfManaged(secret)
+
+	before, err := os.ReadFile(file)
 	if err != nil {
 		return err
 	}
 
-	readData, err := ioutil.ReadFile(tempfile.Name())
+	var stopWatch func() *v1.Secret
+	if o.watchCluster {
+		stopWatch, err = o.watchSecret(secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case o.helmExport != "":
+		err = o.applyHelmExport(file)
+	case o.fromFile != "":
+		var content []byte
+		content, err = os.ReadFile(o.fromFile)
+		if err == nil {
+			err = os.WriteFile(file, content, 0644)
+		}
+	case o.mergeTool != "":
+		err = editor.Merge(o.mergeTool, file, o.mergeBase)
+	case o.sops && editor.IsSOPS(before):
+		logrus.Infof("editing SOPS encrypted content through sops")
+		err = editor.EditSOPS(file)
+	default:
+		err = editor.Edit(file)
+	}
 	if err != nil {
+		if stopWatch != nil {
+			stopWatch()
+		}
 		return err
 	}
 
-	finalSum := md5.Sum(readData)
+	if stopWatch == nil {
+		return nil
+	}
 
-	if originalSum == finalSum {
-		logrus.Infof("no changes done to secret %q", o.secretName)
+	latest := stopWatch()
+	if latest == nil {
 		return nil
 	}
 
-	var updateData map[string]string
+	after, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if o.modifyOptions().IsUnchanged(before, after) {
+		return nil
+	}
 
-	updateByteData := make(map[string][]byte, len(updateData))
-	// 1. Compression gzip
-	var buf bytes.Buffer
-	gzipWriter := gzip.NewWriter(&buf)
+	return o.resolveConcurrentChange(secret, latest, file)
+}
+
+// newDriver returns the driver of the storage of --storage
+func (o *ModifySecretOptions) newDriver() (secrets.StorageDriver, error) {
+	driver, err := o.clientDriver(o.kubeclient)
+	if err != nil || o.readClient == nil {
+		return driver, err
+	}
 
-	_, err = gzipWriter.Write(readData)
+	read, err := o.clientDriver(o.readClient)
 	if err != nil {
-		return fmt.Errorf("erreur lors de la compression gzip : %v", err)
+		return nil, err
 	}
 
-	err = gzipWriter.Close()
+	return &secrets.SplitDriver{Read: read, Write: driver}, nil
+}
+
+// clientDriver returns the driver of the storage working with the client
+func (o *ModifySecretOptions) clientDriver(client kubernetes.Interface) (secrets.StorageDriver, error) {
+	driver, err := secrets.NewDriver(o.storage, client)
 	if err != nil {
-		return fmt.Errorf("erreur lors de la fermeture du writer gzip : %v", err)
+		return nil, err
 	}
 
-	compressedData := buf.Bytes()
+	switch d := driver.(type) {
+	case *secrets.SecretDriver:
+		d.ListFromCache = o.cachedList
+	case *secrets.ConfigMapDriver:
+		d.ListFromCache = o.cachedList
+	}
 
-	// 2. Premier encodage base64
-	encodedSecretLevel1 := base64.StdEncoding.EncodeToString(compressedData)
+	return driver, nil
+}
 
-	updateByteData["release"] = []byte(encodedSecretLevel1)
+// encode encodes the release the way it is stored in the secret
+func (o *ModifySecretOptions) encode(content []byte) ([]byte, error) {
+	if o.normalize {
+		var err error
+		content, err = release.SortKeys(content)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	secret.Data = updateByteData
+	return modify.Encode(content, o.noGzip)
+}
 
-	_, err = secrets.Update(context.TODO(), o.kubeclient, secret)
+// releaseOf decodes the release stored in the secret and returns the key holding it, found the way the editor finds it
+func (o *ModifySecretOptions) releaseOf(secret *v1.Secret) ([]byte, string, error) {
+	key, err := o.modifyOptions().ReleaseKeyOf(secret)
 	if err != nil {
-		return err
+		return nil, "", err
+	}
+
+	content, err := release.Decode(secret.Data[key])
+	return content, key, release.WithKey(err, key)
+}
+
+// parsedRelease parses the release stored in the secret, in the key the editor finds it in
+func (o *ModifySecretOptions) parsedRelease(secret *v1.Secret) (*release.Release, error) {
+	content, key, err := o.releaseOf(secret)
+	if err != nil {
+		return nil, err
 	}
 
-	logrus.Infof("secret %q edited", o.secretName)
+	rel, err := release.Unmarshal(content)
+	return rel, release.WithKey(err, key)
+}
+
+// setsChartMetadata tells whether the chart metadata of the release is set from the command line
+func (o *ModifySecretOptions) setsChartMetadata() bool {
+	return o.chartVersion != "" || o.appVersion != "" || len(o.chartSources) > 0
+}
+
+// validateNotEmpty ensures the flags given on the command line aren't set to an empty value
+func validateNotEmpty(cmd *cobra.Command, names ...string) error {
+	if cmd == nil {
+		return nil
+	}
+
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag != nil && flag.Changed && strings.TrimSpace(flag.Value.String()) == "" {
+			return fmt.Errorf("--%s must not be empty", name)
+		}
+	}
 
 	return nil
 }
 
-// getNamespace takes a set of kubectl flag values and returns the namespace we should be operating in
-func getNamespace(flags *genericclioptions.ConfigFlags) string {
-	namespace, _, err := flags.ToRawKubeConfigLoader().Namespace()
-	if err != nil || len(namespace) == 0 {
-		namespace = "default"
+// countTrue returns how many of the conditions are true
+func countTrue(conditions ...bool) int {
+	count := 0
+	for _, condition := range conditions {
+		if condition {
+			count++
+		}
+	}
+
+	return count
+}
+
+// getNamespace takes a set of kubectl flag values and returns the namespace we should be operating in:
+// the one given with --namespace or set in the kubeconfig context, or else the fallback
+func getNamespace(flags *genericclioptions.ConfigFlags, fallback string) (string, error) {
+	loader := flags.ToRawKubeConfigLoader()
+	namespace, explicit, err := loader.Namespace()
+	// the loader itself answers default when the context sets no namespace, which is only kept when set on purpose
+	if err == nil && namespace != "" && (explicit || namespace != metav1.NamespaceDefault || contextNamespace(flags) == namespace) {
+		return namespace, nil
 	}
-	return namespace
+
+	if fallback == "" {
+		return "", fmt.Errorf("no namespace given with --namespace or set in the kubeconfig context, and no --default-namespace")
+	}
+
+	return fallback, nil
+}
+
+// contextNamespace returns the namespace set in the kubeconfig context in use, if any
+func contextNamespace(flags *genericclioptions.ConfigFlags) string {
+	raw, err := flags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return ""
+	}
+
+	name := raw.CurrentContext
+	if flags.Context != nil && *flags.Context != "" {
+		name = *flags.Context
+	}
+
+	if context, ok := raw.Contexts[name]; ok {
+		return context.Namespace
+	}
+
+	return ""
 }
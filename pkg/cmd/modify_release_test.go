@@ -1,17 +1,32 @@
 package cmd
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestModifySecrets(t *testing.T) {
@@ -31,24 +46,32 @@ func TestModifySecrets(t *testing.T) {
 	testcases := []struct {
 		name     string
 		command  string
-		secret   map[string][]byte
-		expected map[string][]byte
+		format   string
+		release  string
+		expected string
 	}{
 		{
-			name:     "no changes to empty secret",
+			name:     "no changes to release",
 			command:  "touch",
-			secret:   map[string][]byte{},
-			expected: map[string][]byte{},
+			release:  `{"name":"value"}`,
+			expected: `{"name":"value"}`,
 		}, {
-			name:     "no changes to populated secret",
-			command:  "touch",
-			secret:   map[string][]byte{"key": []byte("value")},
-			expected: map[string][]byte{"key": []byte("value")},
-		}, {
-			name:     "changes to populated secret",
+			name:     "changes to release",
 			command:  "sed -i= s/value/updated/",
-			secret:   map[string][]byte{"key": []byte("value")},
-			expected: map[string][]byte{"key": []byte("updated")},
+			release:  `{"name":"value"}`,
+			expected: `{"name":"updated"}`,
+		}, {
+			name:     "changes to release in yaml",
+			command:  `sed -i= s/^\(name:.\)value$/\1updated/`,
+			format:   "yaml",
+			release:  `{"name":"value","version":1}`,
+			expected: `{"name":"updated","version":1}`,
+		}, {
+			name:     "changes to release in json",
+			command:  "sed -i= s/\"value\"/\"updated\"/",
+			format:   "json",
+			release:  `{"name":"value","version":1}`,
+			expected: `{"name":"updated","version":1}`,
 		},
 	}
 
@@ -62,13 +85,14 @@ func TestModifySecrets(t *testing.T) {
 					Namespace:   namespace,
 					Annotations: map[string]string{},
 				},
-				Data: tc.secret,
+				Data: map[string][]byte{"release": encodeRelease(t, tc.release)},
 			})
 
 			modify := ModifySecretOptions{
 				kubeclient: client,
 				secretName: name,
 				namespace:  namespace,
+				format:     tc.format,
 			}
 			require.NoError(t, modify.Run())
 
@@ -80,7 +104,544 @@ func TestModifySecrets(t *testing.T) {
 				namespace, name,
 			)
 			require.NoError(t, err)
-			assert.Equal(t, tc.expected, object.(*v1.Secret).Data)
+			assert.Equal(t, tc.expected, decodeRelease(t, object.(*v1.Secret).Data["release"]))
+		})
+	}
+}
+
+// encodeRelease encodes the release the way Helm stores it in a secret
+func encodeRelease(t *testing.T, release string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(release))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// decodeRelease decodes a release stored in a secret
+func decodeRelease(t *testing.T, data []byte) string {
+	compressed, err := base64.StdEncoding.DecodeString(string(data))
+	require.NoError(t, err)
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+
+	release, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(release)
+}
+
+func TestRecoveryFile(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("EDITOR", "sed -i= s/value/updated/")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"value"}`)},
+	})
+	client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+	}
+	require.Error(t, modify.Run())
+
+	files, err := os.ReadDir(recoveryDir())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	recoveryFile := filepath.Join(recoveryDir(), files[0].Name())
+	content, err := os.ReadFile(recoveryFile)
+	require.NoError(t, err)
+	assert.Equal(t, "name: updated\n", string(content))
+
+	client.ReactionChain = client.ReactionChain[1:]
+	modify.fromFile = recoveryFile
+	require.NoError(t, modify.Run())
+	assert.NoFileExists(t, recoveryFile)
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"updated"}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestRecoveryFileEditedPart(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("EDITOR", "sed -i= s/v1/v2/")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp","config":{"tag":"v1"}}`)},
+	})
+	client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		format:     release.FormatJSON,
+		valuesOnly: true,
+	}
+	require.Error(t, modify.Run())
+
+	files, err := os.ReadDir(recoveryDir())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	recoveryFile := filepath.Join(recoveryDir(), files[0].Name())
+	assert.Equal(t, ".json", filepath.Ext(recoveryFile))
+	assert.Equal(t, fmt.Sprintf("your edits were saved to %s, retry with --values-only --format json --from %s", recoveryFile, recoveryFile), hook.LastEntry().Message)
+
+	client.ReactionChain = client.ReactionChain[1:]
+	modify.fromFile = recoveryFile
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","config":{"tag":"v2"}}`, decodeRelease(t, secret.Data["release"]))
+
+	modify = ModifySecretOptions{chartFile: "templates/NOTES.txt", format: release.FormatJSON}
+	assert.Equal(t, []string{"--chart-file", "templates/NOTES.txt"}, modify.editedPartFlags())
+	assert.Equal(t, ".txt", modify.recoveryExt())
+
+	modify = ModifySecretOptions{notesOnly: true, format: release.FormatYAML}
+	assert.Equal(t, []string{"--notes-only"}, modify.editedPartFlags())
+	assert.Equal(t, ".txt", modify.recoveryExt())
+}
+
+func TestModifyValuesOnly(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "sed -i= s/v1/v2/")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp","manifest":"image: app:v1","config":{"tag":"v1"}}`)},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: name,
+		namespace:  namespace,
+		valuesOnly: true,
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","manifest":"image: app:v1","config":{"tag":"v2"}}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestCompleteWithCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"mysecret","namespace":"mynamespace"}}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: proxied
+  cluster:
+    server: %s
+contexts:
+- name: proxied
+  context:
+    cluster: proxied
+    namespace: mynamespace
+current-context: proxied
+`, server.URL)), 0600))
+
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	testcases := []struct {
+		name     string
+		caFile   string
+		insecure bool
+		err      string
+	}{
+		{
+			name: "unknown authority",
+			err:  "x509",
+		},
+		{
+			name:   "custom certificate authority",
+			caFile: caFile,
+		},
+		{
+			name:     "insecure skip tls verify",
+			insecure: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			modify := NewModifySecretOptions(genericclioptions.IOStreams{})
+			modify.configFlags.KubeConfig = &kubeconfig
+			modify.configFlags.CAFile = &tc.caFile
+			modify.configFlags.Insecure = &tc.insecure
+			require.NoError(t, modify.Complete(nil, []string{"mysecret"}))
+
+			_, err := modify.driver.Get(context.TODO(), modify.secretName, modify.namespace)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
 		})
 	}
 }
+
+func TestModifyWithStorageDriver(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "sed -i= s/value/updated/")
+
+	driver := secrets.NewFakeDriver(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"value"}`)},
+	})
+
+	modify := ModifySecretOptions{
+		driver:     driver,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := driver.Get(context.TODO(), "mysecret", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"updated"}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestModifySOPSValues(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "sops"), []byte("#!/bin/sh\nsed -i s/ENC.v1./ENC[v2]/ \"$1\"\n"), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("EDITOR", "false")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"config":{"password":"ENC[v1]","sops":{"mac":"ENC[mac]"}}}`)},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		valuesOnly: true,
+		sops:       true,
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"config":{"password":"ENC[v2]","sops":{"mac":"ENC[mac]"}}}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestTrailingWhitespaceIsNoChange(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", `sed -i= s/$/\t/`)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"value"}`)},
+	})
+	updates := 0
+	client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		updates++
+		return false, nil, nil
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient:     client,
+		secretName:     "mysecret",
+		namespace:      "mynamespace",
+		trimWhitespace: true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, 0, updates)
+
+	modify.trimWhitespace = false
+	require.NoError(t, modify.Run())
+	assert.Equal(t, 1, updates)
+}
+
+func TestCompleteImpersonation(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"mysecret","namespace":"mynamespace"}}`)
+	}))
+	defer server.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: %s
+contexts:
+- name: cluster
+  context:
+    cluster: cluster
+    namespace: mynamespace
+current-context: cluster
+`, server.URL)), 0600))
+
+	user := "jane"
+	groups := []string{"developers", "auditors"}
+
+	modify := NewModifySecretOptions(genericclioptions.IOStreams{})
+	modify.configFlags.KubeConfig = &kubeconfig
+	modify.configFlags.Impersonate = &user
+	modify.configFlags.ImpersonateGroup = &groups
+	require.NoError(t, modify.Complete(nil, []string{"mysecret"}))
+
+	_, err := modify.driver.Get(context.TODO(), modify.secretName, modify.namespace)
+	require.NoError(t, err)
+
+	header := <-headers
+	assert.Equal(t, "jane", header.Get("Impersonate-User"))
+	assert.Equal(t, groups, header.Values("Impersonate-Group"))
+}
+
+func TestCompleteStorageNamespace(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: cluster
+  context:
+    cluster: cluster
+    namespace: apps
+current-context: cluster
+`), 0600))
+
+	modify := NewModifySecretOptions(genericclioptions.IOStreams{})
+	modify.configFlags.KubeConfig = &kubeconfig
+	require.NoError(t, modify.Complete(nil, []string{"mysecret"}))
+	assert.Equal(t, "apps", modify.namespace)
+
+	modify.storageNamespace = "helm-releases"
+	require.NoError(t, modify.Complete(nil, []string{"mysecret"}))
+	assert.Equal(t, "helm-releases", modify.namespace)
+}
+
+func TestGetNamespaceFallback(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: no-namespace
+  context:
+    cluster: cluster
+- name: default-namespace
+  context:
+    cluster: cluster
+    namespace: default
+current-context: no-namespace
+`), 0600))
+
+	flags := genericclioptions.NewConfigFlags(true)
+	flags.KubeConfig = &kubeconfig
+
+	namespace, err := getNamespace(flags, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", namespace)
+
+	namespace, err = getNamespace(flags, "sandbox")
+	require.NoError(t, err)
+	assert.Equal(t, "sandbox", namespace)
+
+	_, err = getNamespace(flags, "")
+	assert.EqualError(t, err, "no namespace given with --namespace or set in the kubeconfig context, and no --default-namespace")
+
+	// the flags keep the loader they built, new ones are needed to change them
+	contextName := "default-namespace"
+	flags = genericclioptions.NewConfigFlags(true)
+	flags.KubeConfig = &kubeconfig
+	flags.Context = &contextName
+	namespace, err = getNamespace(flags, "")
+	require.NoError(t, err)
+	assert.Equal(t, "default", namespace)
+
+	explicit := "default"
+	flags = genericclioptions.NewConfigFlags(true)
+	flags.KubeConfig = &kubeconfig
+	flags.Namespace = &explicit
+	namespace, err = getNamespace(flags, "")
+	require.NoError(t, err)
+	assert.Equal(t, "default", namespace)
+}
+
+func TestLocalFile(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("EDITOR", "sed -i= s/myapp/renamed/")
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "secret.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: sh.helm.release.v1.myapp.v1
+  namespace: mynamespace
+data:
+  release: `+base64.StdEncoding.EncodeToString(encodeRelease(t, `{"name":"myapp"}`))+`
+`), 0600))
+
+	modify := NewModifySecretOptions(genericclioptions.IOStreams{})
+	modify.localFile = manifest
+	modify.format = "json"
+	require.NoError(t, modify.Complete(nil, nil))
+	require.NoError(t, modify.Validate())
+	assert.Equal(t, "sh.helm.release.v1.myapp.v1", modify.secretName)
+	assert.Equal(t, "mynamespace", modify.namespace)
+	assert.Nil(t, modify.kubeclient)
+	require.NoError(t, modify.Run())
+
+	secret, err := (&secrets.FileDriver{Path: manifest}).Read()
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"renamed"}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestReplaceFrom(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp"}`)},
+	})
+	updates := 0
+	client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		updates++
+		return false, nil, nil
+	})
+
+	file := filepath.Join(t.TempDir(), "new.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("name: renamed\n"), 0600))
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		format:     "yaml",
+		fromFile:   file,
+		dryRun:     true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Contains(t, out.String(), "+name: renamed")
+	assert.Equal(t, 0, updates)
+
+	require.NoError(t, os.WriteFile(file, []byte("name: [unclosed\n"), 0600))
+	modify.dryRun = false
+	assert.Error(t, modify.Run())
+	assert.Equal(t, 0, updates)
+
+	require.NoError(t, os.WriteFile(file, []byte("name: renamed\n"), 0600))
+	require.NoError(t, modify.Run())
+	assert.Equal(t, 1, updates)
+}
+
+func TestReplaceFromExclusiveWithFrom(t *testing.T) {
+	cmd := NewCmdModifySecret(genericclioptions.IOStreams{})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	cmd.SetArgs([]string{"mysecret", "--from", "a.yaml", "--replace-from", "b.yaml"})
+	assert.EqualError(t, cmd.Execute(), "if any flags in the group [from replace-from] are set none of the others can be; [from replace-from] were all set")
+}
+
+func TestReadServer(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"}}
+	read := fake.NewSimpleClientset(secret)
+	write := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient: write,
+		readClient: read,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		literals:   map[string]string{"password": "newpass"},
+	}
+	require.NoError(t, modify.Run())
+
+	assertVerbs(t, read.Actions(), "get")
+	assertVerbs(t, write.Actions(), "update")
+
+	updated, err := write.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "newpass", string(updated.Data["password"]))
+}
+
+func TestReadServerVerify(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "sed -i= s/value/updated/")
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"value"}`)},
+	}
+	read := fake.NewSimpleClientset(secret)
+	write := fake.NewSimpleClientset(secret)
+
+	// the replica never catches up, the release is verified against the server it was written to
+	modify := ModifySecretOptions{
+		kubeclient: write,
+		readClient: read,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		verify:     true,
+	}
+	require.NoError(t, modify.Run())
+
+	assertVerbs(t, read.Actions(), "get")
+	assertVerbs(t, write.Actions(), "update", "get")
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaces returns the namespaces to operate in: all of them with --all-namespaces,
+// the namespaces matching --namespace-selector, or the namespace of the command line
+func (o *ModifySecretOptions) namespaces() ([]string, error) {
+	if o.allNamespaces {
+		return []string{metav1.NamespaceAll}, nil
+	}
+
+	if o.namespaceSelector == "" {
+		return []string{o.namespace}, nil
+	}
+
+	list, err := o.kubeclient.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: o.namespaceSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/modify"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// runEditNested lets the user edit the YAML document held by the key given with --key, decoded.
+// The document is edited as text, so its comments are kept, and stored with the layers it was stored with.
+func (o *ModifySecretOptions) runEditNested() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	err = secrets.CheckMutable(secret)
+	if err != nil {
+		return err
+	}
+
+	value, ok := secret.Data[o.key]
+	if !ok {
+		return fmt.Errorf("no key %q in secret %q", o.key, secret.Name)
+	}
+
+	before, layers, err := release.Unwrap(value)
+	if err != nil {
+		return release.WithKey(err, o.key)
+	}
+
+	err = validateYAML(before)
+	if err != nil {
+		return fmt.Errorf("key %q doesn't hold a YAML document: %v", o.key, err)
+	}
+
+	after, err := o.editNested(before, secret)
+	if err != nil {
+		return err
+	}
+
+	opts := o.modifyOptions()
+	if opts.IsUnchanged(before, after) {
+		return o.runPatchMetadata()
+	}
+
+	err = validateYAML(after)
+	if err != nil {
+		o.saveEdits(after)
+		return fmt.Errorf("the edited key %q is not valid YAML, the secret was left unchanged: %v", o.key, err)
+	}
+
+	encoded, err := release.Wrap(after, layers)
+	if err != nil {
+		return fmt.Errorf("failed to encode key %q: %v", o.key, err)
+	}
+	secret.Data[o.key] = encoded
+	o.applyMetadata(secret)
+
+	if o.dryRun {
+		logrus.Infof("key %q of secret %q edited (dry run)", o.key, o.secretName)
+		return o.printDiff(before, after)
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.Update(context.TODO(), secret, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	if isRecoveryFile(o.fromFile) {
+		os.Remove(o.fromFile)
+	}
+
+	logrus.Infof("key %q of secret %q edited", o.key, o.secretName)
+	return nil
+}
+
+// editNested lets the user edit the decoded value of the key, in memory with --edit-fifo or --edit-stdio,
+// or else in a temporary file named after the key
+func (o *ModifySecretOptions) editNested(content []byte, secret *v1.Secret) ([]byte, error) {
+	if o.editFIFO != "" || o.editStdio {
+		return o.editContent(content, secret)
+	}
+
+	extension := filepath.Ext(o.key)
+	if extension == "" {
+		extension = ".yaml"
+	}
+
+	tempfile, err := os.CreateTemp("", fmt.Sprintf("%s-%s-*%s", o.namespace, o.secretName, extension))
+	if err != nil {
+		return nil, err
+	}
+	tempfile.Close()
+	defer modify.RemoveFile(tempfile.Name())
+
+	err = os.WriteFile(tempfile.Name(), content, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.edit(tempfile.Name(), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(tempfile.Name())
+}
+
+// validateYAML checks that the content parses as a YAML document
+func validateYAML(content []byte) error {
+	var document interface{}
+	return yaml.Unmarshal(content, &document)
+}
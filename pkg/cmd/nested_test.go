@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunEditNested(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("EDITOR", "sed -i= s/1$/3/")
+
+	rel := encodeRelease(t, `{"name":"myapp"}`)
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data: map[string][]byte{
+			"config.yaml": []byte("# replicas of the app\nreplicas: 1\n"),
+			"release":     rel,
+		},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		key:        "config.yaml",
+		nested:     true,
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "# replicas of the app\nreplicas: 3\n", string(secret.Data["config.yaml"]))
+	assert.Equal(t, rel, secret.Data["release"])
+}
+
+func TestRunEditNestedInvalidYAML(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	t.Setenv("TMPDIR", t.TempDir())
+
+	from := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(from, []byte("replicas: [3\n"), 0644))
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"config.yaml": []byte("replicas: 1\n")},
+	})
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		key:        "config.yaml",
+		nested:     true,
+		fromFile:   from,
+		format:     "json",
+	}
+	err := modify.Run()
+	assert.ErrorContains(t, err, `the edited key "config.yaml" is not valid YAML, the secret was left unchanged`)
+
+	files, err := os.ReadDir(recoveryDir())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	recoveryFile := filepath.Join(recoveryDir(), files[0].Name())
+	assert.Equal(t, ".yaml", filepath.Ext(recoveryFile))
+	assert.Equal(t, fmt.Sprintf("your edits were saved to %s, retry with --nested --key config.yaml --from %s", recoveryFile, recoveryFile), hook.LastEntry().Message)
+
+	secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "replicas: 1\n", string(secret.Data["config.yaml"]))
+
+	modify.key = "missing"
+	assert.EqualError(t, modify.Run(), `no key "missing" in secret "mysecret"`)
+}
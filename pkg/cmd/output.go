@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// writeJSON prints v as JSON for -o json, indented with --json-indent spaces.
+// Like jq, an indentation of 0 prints a single line, as --compact-json does.
+func (o *ModifySecretOptions) writeJSON(v interface{}) error {
+	var (
+		out []byte
+		err error
+	)
+	if o.compactJSON || o.jsonIndent == 0 {
+		out, err = json.Marshal(v)
+	} else {
+		out, err = json.MarshalIndent(v, "", strings.Repeat(" ", o.jsonIndent))
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = o.IOStreams.Out.Write(append(out, '\n'))
+	return err
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+)
+
+// runRecoverPartial prints the release of the secret to stdout, as decoded, without editing it.
+// When its gzip stream is truncated or corrupt, the content decompressed before the error is printed,
+// so at least part of a corrupted release can be recovered.
+func (o *ModifySecretOptions) runRecoverPartial() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	key, err := o.modifyOptions().ReleaseKeyOf(secret)
+	if err != nil {
+		return err
+	}
+
+	content, err := release.Decode(secret.Data[key])
+	var truncated *release.TruncatedError
+	if errors.As(err, &truncated) {
+		logrus.Warnf("THE RELEASE OF SECRET %q IS CORRUPTED: %v", o.secretName, truncated)
+		logrus.Warnf("only the %d bytes decompressed before the error are printed, they are incomplete and can't be stored back as is", len(truncated.Partial))
+		content = truncated.Partial
+	} else if err != nil {
+		return release.WithKey(err, key)
+	} else {
+		logrus.Infof("the release of secret %q decodes completely", o.secretName)
+	}
+
+	_, err = o.IOStreams.Out.Write(content)
+	return err
+}
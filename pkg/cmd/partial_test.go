@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunAllowPartial(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	var content strings.Builder
+	content.WriteString(`{"name":"myapp","manifest":"`)
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&content, "line %d of the manifest\\n", i)
+	}
+	content.WriteString(`"}`)
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write([]byte(content.String()))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	truncated := compressed.Bytes()[:compressed.Len()/2]
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": release.EncodeUncompressed(truncated)},
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{Out: out},
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		format:     release.FormatYAML,
+	}
+	err = modify.Run()
+	assert.ErrorIs(t, err, release.ErrDecode)
+	assert.Contains(t, err.Error(), `failed to gzip-decode key "release": the gzip stream is truncated or corrupt`)
+
+	modify.allowPartial = true
+	require.NoError(t, modify.Run())
+	assert.NotEmpty(t, out.String())
+	assert.True(t, strings.HasPrefix(content.String(), out.String()))
+	assert.Less(t, out.Len(), content.Len())
+
+	// a complete release is found in the only base64+gzip key when the configured one is missing
+	client = fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"payload": encodeRelease(t, `{"name":"myapp"}`), "token": []byte("s3cr3t")},
+	})
+	out.Reset()
+	modify = ModifySecretOptions{
+		IOStreams:    genericclioptions.IOStreams{Out: out},
+		kubeclient:   client,
+		secretName:   "mysecret",
+		namespace:    "mynamespace",
+		format:       release.FormatYAML,
+		releaseKey:   "release",
+		allowPartial: true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, `{"name":"myapp"}`, out.String())
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// isNamePattern tells whether the secret name given as argument is a glob pattern
+func isNamePattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// statusRollBackFailed is the status of a secret which keeps its edit after a failed roll back
+const statusRollBackFailed = "edited, roll back failed"
+
+// patternResult is the outcome of setting the keys of one secret matching the name pattern
+type patternResult struct {
+	secret string
+	status string
+	err    error
+}
+
+// runSetKeysMatching sets the given keys of every secret of the namespace whose name matches the pattern
+// given as argument, with filepath.Match semantics, and prints the outcome per secret.
+// Secrets are edited one after the other and a failure doesn't stop the others, unless --atomic is set.
+func (o *ModifySecretOptions) runSetKeysMatching(values map[string][]byte) error {
+	pattern := o.secretName
+	items, err := o.driver.List(context.TODO(), o.namespace, "", "")
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, secret := range items {
+		if ok, _ := filepath.Match(pattern, secret.Name); ok {
+			names = append(names, secret.Name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return fmt.Errorf("no secret in namespace %q matches %q", o.namespace, pattern)
+	}
+
+	var results []patternResult
+	if o.atomic {
+		results = o.setKeysAtomically(names, values)
+	} else {
+		results = o.setKeysEach(names, values)
+	}
+	o.secretName = pattern
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SECRET\tRESULT")
+	failed := 0
+	kept := []string{}
+	for _, result := range results {
+		if result.status == statusRollBackFailed {
+			kept = append(kept, strconv.Quote(result.secret))
+		}
+		if result.err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\tfailed: %v\n", result.secret, result.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", result.secret, result.status)
+	}
+	w.Flush()
+
+	if len(kept) > 0 {
+		return fmt.Errorf("%d of %d secrets failed, and %d could not be rolled back and keep the edit: %s", failed, len(results), len(kept), strings.Join(kept, ", "))
+	}
+	if failed > 0 && o.atomic {
+		return fmt.Errorf("%d of %d secrets failed, none was edited", failed, len(results))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d secrets failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// editedStatus is the status of a secret whose keys were set
+func (o *ModifySecretOptions) editedStatus() string {
+	if o.dryRun {
+		return "edited (dry run)"
+	}
+
+	return "edited"
+}
+
+// setKeysEach sets the keys of each secret, applying the edits which succeed
+func (o *ModifySecretOptions) setKeysEach(names []string, values map[string][]byte) []patternResult {
+	results := []patternResult{}
+	for _, name := range names {
+		o.secretName = name
+		err := o.runSetKeys(values)
+		results = append(results, patternResult{secret: name, status: o.editedStatus(), err: err})
+	}
+
+	return results
+}
+
+// setKeysAtomically edits all the secrets or none: every edit is prepared and confirmed before the first
+// update, and the secrets already updated are restored when an update fails
+func (o *ModifySecretOptions) setKeysAtomically(names []string, values map[string][]byte) []patternResult {
+	results := make([]patternResult, len(names))
+	originals := make([]*v1.Secret, len(names))
+	edited := make([]*v1.Secret, len(names))
+	failed := false
+	for i, name := range names {
+		o.secretName = name
+		results[i] = patternResult{secret: name, status: "not edited"}
+
+		var err error
+		originals[i], edited[i], err = o.setKeys(values)
+		if err == nil {
+			err = o.confirmName()
+		}
+		if err != nil {
+			results[i].err = err
+			failed = true
+		}
+	}
+
+	if failed {
+		return results
+	}
+
+	if o.dryRun {
+		for i := range results {
+			results[i].status = o.editedStatus()
+		}
+		return results
+	}
+
+	for i := range names {
+		updated, err := o.driver.Update(context.TODO(), edited[i], o.fieldManager)
+		if err != nil {
+			results[i].err = err
+			o.rollBack(results[:i], originals[:i], edited[:i])
+			return results
+		}
+		edited[i] = updated
+		results[i].status = o.editedStatus()
+	}
+
+	return results
+}
+
+// rollBack restores the data, labels and annotations of the secrets updated before a failure
+func (o *ModifySecretOptions) rollBack(results []patternResult, originals, updated []*v1.Secret) {
+	for i := range results {
+		restored := updated[i].DeepCopy()
+		restored.Data = originals[i].Data
+		restored.Labels = originals[i].Labels
+		restored.Annotations = originals[i].Annotations
+
+		_, err := o.driver.Update(context.TODO(), restored, o.fieldManager)
+		if err != nil {
+			logrus.Errorf("failed to roll back secret %q, it keeps the edit: %v", results[i].secret, err)
+			results[i].status = statusRollBackFailed
+			continue
+		}
+		results[i].status = "rolled back"
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// runPruneHistory deletes the revisions of the release but the most recent ones, like helm upgrade --history-max.
+// The deployed revision is always kept.
+func (o *ModifySecretOptions) runPruneHistory() error {
+	items, err := o.driver.List(context.TODO(), o.namespace, fmt.Sprintf("%s,name=%s", releaseSelector, o.secretName), o.fieldSelector)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return apierrors.NewNotFound(schema.GroupResource{Group: "helm.sh", Resource: "releases"}, o.secretName)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		vi, _ := strconv.Atoi(items[i].Labels["version"])
+		vj, _ := strconv.Atoi(items[j].Labels["version"])
+		return vi > vj
+	})
+
+	pruned := []string{}
+	for i, secret := range items {
+		if i < o.keep || o.revisionStatus(&secret) == release.StatusDeployed {
+			continue
+		}
+		pruned = append(pruned, secret.Name)
+	}
+
+	if len(pruned) == 0 {
+		logrus.Infof("release %q has no more than %d revisions, nothing to prune", o.secretName, o.keep)
+		return nil
+	}
+
+	if o.dryRun {
+		for _, name := range pruned {
+			logrus.Infof("revision %q would be deleted (dry run)", name)
+		}
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range pruned {
+		err = o.driver.Delete(context.TODO(), name, o.namespace)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("revision %q deleted", name)
+	}
+
+	return nil
+}
+
+// revisionStatus returns the status of the revision stored in the secret,
+// read from its label when the release can't be decoded
+func (o *ModifySecretOptions) revisionStatus(secret *v1.Secret) string {
+	rel, err := o.parsedRelease(secret)
+	if err != nil {
+		return secret.Labels["status"]
+	}
+
+	return rel.Info.Status
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunPruneHistory(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	objects := []runtime.Object{
+		releaseSecret(t, namespace, "other", 1, `{"name":"other","version":1,"info":{"status":"superseded"}}`),
+	}
+	for version := 1; version <= 6; version++ {
+		status := "superseded"
+		if version == 3 {
+			status = "deployed"
+		}
+		objects = append(objects, releaseSecret(t, namespace, "myapp", version, fmt.Sprintf(`{"name":"myapp","version":%d,"info":{"status":%q}}`, version, status)))
+	}
+	client := fake.NewSimpleClientset(objects...)
+
+	modify := ModifySecretOptions{
+		kubeclient:   client,
+		secretName:   "myapp",
+		namespace:    namespace,
+		pruneHistory: true,
+		keep:         2,
+		dryRun:       true,
+	}
+	require.NoError(t, modify.Run())
+	assert.ElementsMatch(t, []string{
+		"sh.helm.release.v1.myapp.v1", "sh.helm.release.v1.myapp.v2", "sh.helm.release.v1.myapp.v3",
+		"sh.helm.release.v1.myapp.v4", "sh.helm.release.v1.myapp.v5", "sh.helm.release.v1.myapp.v6",
+		"sh.helm.release.v1.other.v1",
+	}, secretNames(t, client, namespace))
+
+	modify.dryRun = false
+	require.NoError(t, modify.Run())
+	assert.ElementsMatch(t, []string{
+		"sh.helm.release.v1.myapp.v3", "sh.helm.release.v1.myapp.v5", "sh.helm.release.v1.myapp.v6",
+		"sh.helm.release.v1.other.v1",
+	}, secretNames(t, client, namespace))
+}
+
+// secretNames returns the names of the secrets of the namespace
+func secretNames(t *testing.T, client *fake.Clientset, namespace string) []string {
+	list, err := client.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, secret := range list.Items {
+		names = append(names, secret.Name)
+	}
+
+	return names
+}
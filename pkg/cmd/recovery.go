@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+)
+
+// recoveryDir is the directory holding edits that could not be applied
+func recoveryDir() string {
+	return filepath.Join(os.TempDir(), "kubectl-modify-secret")
+}
+
+// saveEdits saves the edited content to a recovery file and tells how to retry with it,
+// along with the flags selecting what was edited
+func (o *ModifySecretOptions) saveEdits(content []byte) {
+	recoveryFile, err := saveRecoveryFile(o.namespace, o.secretName, o.recoveryExt(), content)
+	if err != nil {
+		return
+	}
+
+	retry := append(o.editedPartFlags(), "--from", recoveryFile)
+	logrus.Warnf("your edits were saved to %s, retry with %s", recoveryFile, strings.Join(retry, " "))
+}
+
+// editedPartFlags returns the flags selecting the part of the release edited and its format
+func (o *ModifySecretOptions) editedPartFlags() []string {
+	switch {
+	case o.nested:
+		return []string{"--nested", "--key", o.key}
+	case o.chartFile != "":
+		return []string{"--chart-file", o.chartFile}
+	case o.notesOnly:
+		return []string{"--notes-only"}
+	}
+
+	flags := []string{}
+	if o.valuesOnly || o.mergeValuesFile != "" {
+		flags = append(flags, "--values-only")
+	}
+	if o.format == release.FormatJSON {
+		flags = append(flags, "--format", release.FormatJSON)
+	}
+
+	return flags
+}
+
+// recoveryExt returns the extension of the recovery file, matching the format of the edited content
+func (o *ModifySecretOptions) recoveryExt() string {
+	switch {
+	case o.nested:
+		return ".yaml"
+	case o.chartFile != "":
+		return filepath.Ext(o.chartFile)
+	case o.notesOnly:
+		return ".txt"
+	case o.format == release.FormatJSON:
+		return ".json"
+	}
+
+	return ".yaml"
+}
+
+// saveRecoveryFile writes the edited content to a recovery file with the given extension and returns its path
+func saveRecoveryFile(namespace, secretName, ext string, content []byte) (string, error) {
+	err := os.MkdirAll(recoveryDir(), 0700)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(recoveryDir(), fmt.Sprintf("%s-%s-*%s", namespace, secretName, ext))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = f.Write(content)
+	if err != nil {
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// isRecoveryFile reports whether the file was created by saveRecoveryFile
+func isRecoveryFile(file string) bool {
+	if file == "" {
+		return false
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+
+	return filepath.Dir(abs) == recoveryDir()
+}
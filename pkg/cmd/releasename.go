@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// runPrintSecretName prints the name of the secret storing the revision of --revision of the release
+// given as argument, its latest revision when not set, so scripts can feed it to other commands
+func (o *ModifySecretOptions) runPrintSecretName() error {
+	if o.revision == 0 {
+		secret, err := secrets.Latest(context.TODO(), o.driver, o.secretName, o.namespace)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(o.IOStreams.Out, secret.Name)
+		return err
+	}
+
+	items, err := o.driver.List(context.TODO(), o.namespace, fmt.Sprintf("owner=helm,name=%s,version=%d", o.secretName, o.revision), "")
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return apierrors.NewNotFound(schema.GroupResource{Group: "helm.sh", Resource: "releases"}, fmt.Sprintf("%s revision %d", o.secretName, o.revision))
+	}
+
+	_, err = fmt.Fprintln(o.IOStreams.Out, items[0].Name)
+	return err
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunPrintSecretName(t *testing.T) {
+	const namespace = "mynamespace"
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 4, `{"name":"myapp","version":4}`),
+		releaseSecret(t, namespace, "myapp", 5, `{"name":"myapp","version":5}`),
+		releaseSecret(t, namespace, "other", 9, `{"name":"other","version":9}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:       genericclioptions.IOStreams{Out: out},
+		kubeclient:      client,
+		secretName:      "myapp",
+		namespace:       namespace,
+		printSecretName: true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, "sh.helm.release.v1.myapp.v5\n", out.String())
+
+	out.Reset()
+	modify.revision = 4
+	require.NoError(t, modify.Run())
+	assert.Equal(t, "sh.helm.release.v1.myapp.v4\n", out.String())
+
+	out.Reset()
+	modify.revision = 3
+	err := modify.Run()
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.EqualError(t, err, `releases.helm.sh "myapp revision 3" not found`)
+	assert.Empty(t, out.String())
+
+	modify.secretName = "missing"
+	modify.revision = 0
+	assert.True(t, apierrors.IsNotFound(modify.Run()))
+
+	modify = ModifySecretOptions{secretName: "myapp", revision: 2}
+	assert.EqualError(t, modify.Validate(), "--revision requires --print-secret-name")
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+)
+
+// runRepair rewrites a release wrapped in extra base64 or gzip layers the way Helm stores it,
+// so Helm can read it again
+func (o *ModifySecretOptions) runRepair() error {
+	secret, err := o.driver.Get(context.TODO(), o.secretName, o.namespace)
+	if err != nil {
+		return err
+	}
+
+	err = secrets.CheckMutable(secret)
+	if err != nil {
+		return err
+	}
+
+	key, err := o.modifyOptions().ReleaseKeyOf(secret)
+	if err != nil {
+		return err
+	}
+
+	content, layers, err := release.Unwrap(secret.Data[key])
+	if err != nil {
+		return release.WithKey(err, key)
+	}
+
+	_, err = release.Unmarshal(content)
+	if err != nil {
+		return release.WithKey(err, key)
+	}
+
+	if layers.Equal(release.HelmLayers) || layers.Equal(release.Layers{release.LayerBase64}) {
+		logrus.Infof("release %q is stored as %s, which Helm reads, nothing to repair", o.secretName, layers)
+		return nil
+	}
+
+	logrus.Infof("release %q is stored as %s, stripping %s", o.secretName, layers, strippedLayers(layers))
+
+	encoded, err := release.Encode(content)
+	if err != nil {
+		return err
+	}
+	secret.Data[key] = encoded
+
+	if o.dryRun {
+		logrus.Infof("release %q repaired (dry run)", o.secretName)
+		return nil
+	}
+
+	err = o.confirmName()
+	if err != nil {
+		return err
+	}
+
+	_, err = o.driver.Update(context.TODO(), secret, o.fieldManager)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("release %q repaired, stored as %s", o.secretName, release.HelmLayers)
+	return nil
+}
+
+// strippedLayers are the layers found on top of the outer base64 and the inner gzip Helm uses
+func strippedLayers(layers release.Layers) release.Layers {
+	stripped := append(release.Layers{}, layers...)
+	for i, layer := range stripped {
+		if layer == release.LayerBase64 {
+			stripped = append(stripped[:i], stripped[i+1:]...)
+			break
+		}
+	}
+
+	for i := len(stripped) - 1; i >= 0; i-- {
+		if stripped[i] == release.LayerGzip {
+			stripped = append(stripped[:i], stripped[i+1:]...)
+			break
+		}
+	}
+
+	return stripped
+}
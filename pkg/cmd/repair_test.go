@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunRepair(t *testing.T) {
+	const (
+		name      = "sh.helm.release.v1.myapp.v1"
+		namespace = "mynamespace"
+		content   = `{"name":"myapp"}`
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+
+	testcases := []struct {
+		name     string
+		layers   release.Layers
+		stripped string
+	}{
+		{
+			name:     "double base64",
+			layers:   release.Layers{release.LayerBase64, release.LayerBase64, release.LayerGzip},
+			stripped: "stripping base64",
+		},
+		{
+			name:     "double gzip",
+			layers:   release.Layers{release.LayerBase64, release.LayerGzip, release.LayerGzip},
+			stripped: "stripping gzip",
+		},
+		{
+			name:     "helm encoding",
+			layers:   release.HelmLayers,
+			stripped: "nothing to repair",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored, err := release.Wrap([]byte(content), tc.layers)
+			require.NoError(t, err)
+
+			client := fake.NewSimpleClientset(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Data:       map[string][]byte{"release": stored},
+			})
+
+			hook := test.NewGlobal()
+			defer hook.Reset()
+
+			modify := ModifySecretOptions{kubeclient: client, secretName: name, namespace: namespace, repair: true}
+			require.NoError(t, modify.Run())
+			assert.Contains(t, hook.AllEntries()[0].Message, tc.stripped)
+
+			secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, release.HelmLayers, release.DetectLayers(secret.Data["release"]))
+			assert.Equal(t, content, decodeRelease(t, secret.Data["release"]))
+		})
+	}
+}
+
+func TestStrippedLayers(t *testing.T) {
+	b64, gz := release.LayerBase64, release.LayerGzip
+	assert.Equal(t, release.Layers{b64}, strippedLayers(release.Layers{b64, b64, gz}))
+	assert.Equal(t, release.Layers{gz, b64}, strippedLayers(release.Layers{b64, gz, b64, gz}))
+	assert.Equal(t, release.Layers{}, strippedLayers(release.Layers{gz}))
+}
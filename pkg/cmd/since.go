@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// deployedSince tells whether the release was last deployed within the duration of --since, always true without it.
+// Releases without a valid last_deployed timestamp are left out.
+func (o *ModifySecretOptions) deployedSince(rel *release.Release) bool {
+	if o.since == 0 {
+		return true
+	}
+
+	deployed, err := time.Parse(time.RFC3339, rel.Info.LastDeployed)
+	if err != nil {
+		return false
+	}
+
+	return time.Since(deployed) <= o.since
+}
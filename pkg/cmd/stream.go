@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/editor"
+	v1 "k8s.io/api/core/v1"
+)
+
+// editContent lets the program driving the edit through --edit-fifo or --edit-stdio edit the release in memory
+func (o *ModifySecretOptions) editContent(content []byte, secret *v1.Secret) ([]byte, error) {
+	warnIfManaged(secret)
+
+	if o.editFIFO != "" {
+		return editFIFO(o.editFIFO, content)
+	}
+
+	return editStream(o.IOStreams.In, o.IOStreams.Out, content)
+}
+
+// editFIFO hands the content to the program at the other end of the named pipe,
+// then reads the edited content back from it until the program closes the pipe
+func editFIFO(path string, content []byte) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return nil, fmt.Errorf("%s is not a named pipe", path)
+	}
+
+	fifo, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fifo.Write(content)
+	if err != nil {
+		fifo.Close()
+		return nil, err
+	}
+
+	err = fifo.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+// editStream writes the content to out, then reads the edited content from in until EOF
+func editStream(in io.Reader, out io.Writer, content []byte) ([]byte, error) {
+	_, err := out.Write(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(in)
+}
+
+// transformContent pipes the release to the program of --transform and returns what it prints
+func (o *ModifySecretOptions) transformContent(content []byte, secret *v1.Secret) ([]byte, error) {
+	warnIfManaged(secret)
+
+	return editor.Transform(o.transform, content)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/config"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEditStdio(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp"}`)},
+	})
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:  genericclioptions.IOStreams{In: strings.NewReader(`{"name":"renamed"}`), Out: out},
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		format:     release.FormatJSON,
+		editStdio:  true,
+	}
+	require.NoError(t, modify.Run())
+	assert.JSONEq(t, `{"name":"myapp"}`, out.String())
+
+	secret, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"renamed"}`, decodeRelease(t, secret.Data["release"]))
+}
+
+func TestValidateEditStdioConfirmName(t *testing.T) {
+	modify := ModifySecretOptions{format: release.FormatYAML, args: []string{"mysecret"}, namespace: "prod", editStdio: true, requireConfirmName: true}
+	assert.EqualError(t, modify.Validate(), "--edit-stdio cannot be used when the secret name must be confirmed, stdin holds the edited release; use --edit-fifo instead")
+
+	modify.requireConfirmName = false
+	modify.config = &config.Config{ConfirmName: []string{"prod*"}}
+	assert.EqualError(t, modify.Validate(), "--edit-stdio cannot be used when the secret name must be confirmed, stdin holds the edited release; use --edit-fifo instead")
+
+	modify.dryRun = true
+	assert.NoError(t, modify.Validate())
+}
+
+func TestTransform(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"name":"myapp"}`)},
+	}
+	client := fake.NewSimpleClientset(secret)
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		secretName: "mysecret",
+		namespace:  "mynamespace",
+		transform:  "false",
+	}
+	assert.EqualError(t, modify.Run(), `transform "false" failed: exit status 1`)
+	stored, err := client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, secret.Data, stored.Data)
+
+	modify.transform = "sed s/myapp/renamed/"
+	require.NoError(t, modify.Run())
+	stored, err = client.CoreV1().Secrets("mynamespace").Get(context.TODO(), "mysecret", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"renamed"}`, decodeRelease(t, stored.Data["release"]))
+}
+
+func TestEditFIFONotANamedPipe(t *testing.T) {
+	_, err := editFIFO(t.TempDir(), []byte("content"))
+	assert.Error(t, err)
+
+	_, err = editFIFO(filepath.Join(t.TempDir(), "missing"), []byte("content"))
+	assert.Error(t, err)
+}
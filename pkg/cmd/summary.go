@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	"k8s.io/utils/strings/slices"
+)
+
+// maxSuperseded is the number of superseded revisions above which a release is flagged for pruning,
+// the default of helm upgrade --history-max
+const maxSuperseded = 10
+
+// summaryStatuses are the statuses always reported by --summary, in order
+var summaryStatuses = []string{release.StatusDeployed, release.StatusSuperseded, "failed", "pending"}
+
+// releaseSummary counts the revisions of a release
+type releaseSummary struct {
+	name       string
+	namespace  string
+	revisions  int
+	superseded int
+}
+
+// runSummary counts the revisions of the Helm releases in the namespaces to operate in, by status and by release
+func (o *ModifySecretOptions) runSummary() error {
+	items, err := o.releaseSecrets()
+	if err != nil {
+		return err
+	}
+
+	statuses := map[string]int{}
+	releases := []*releaseSummary{}
+	byRelease := map[string]*releaseSummary{}
+	for i := range items {
+		secret := &items[i]
+		rel, err := o.listedRelease(secret)
+		if err != nil {
+			logrus.Warnf("skipping secret %q: %v", secret.Name, err)
+			continue
+		}
+
+		if !o.deployedSince(rel) {
+			continue
+		}
+
+		status := rel.Info.Status
+		if strings.HasPrefix(status, "pending") {
+			status = "pending"
+		}
+		statuses[status]++
+
+		key := secret.Namespace + "/" + rel.Name
+		summary, ok := byRelease[key]
+		if !ok {
+			summary = &releaseSummary{name: rel.Name, namespace: secret.Namespace}
+			byRelease[key] = summary
+			releases = append(releases, summary)
+		}
+		summary.revisions++
+		if rel.Info.Status == release.StatusSuperseded {
+			summary.superseded++
+		}
+	}
+
+	if o.output == "jsonl" {
+		return o.printSummaryJSONLines(releases)
+	}
+
+	others := []string{}
+	for status := range statuses {
+		if !slices.Contains(summaryStatuses, status) {
+			others = append(others, status)
+		}
+	}
+	sort.Strings(others)
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tREVISIONS")
+	for _, status := range append(summaryStatuses, others...) {
+		fmt.Fprintf(w, "%s\t%d\n", status, statuses[status])
+	}
+	w.Flush()
+
+	fmt.Fprintln(o.IOStreams.Out)
+
+	w = tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RELEASE\tNAMESPACE\tREVISIONS\tSUPERSEDED\tNOTE")
+	for _, summary := range releases {
+		note := ""
+		if summary.superseded > maxSuperseded {
+			note = fmt.Sprintf("prune: more than %d superseded revisions", maxSuperseded)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", summary.name, summary.namespace, summary.revisions, summary.superseded, note)
+	}
+
+	return w.Flush()
+}
+
+// summaryEntry is a release printed by --summary -o jsonl
+type summaryEntry struct {
+	Release    string `json:"release"`
+	Namespace  string `json:"namespace"`
+	Revisions  int    `json:"revisions"`
+	Superseded int    `json:"superseded"`
+	Prune      bool   `json:"prune"`
+}
+
+// printSummaryJSONLines prints the counts of each release as a JSON object on its own line.
+// Unlike --list, the lines come once all the revisions are counted.
+func (o *ModifySecretOptions) printSummaryJSONLines(releases []*releaseSummary) error {
+	encoder := json.NewEncoder(o.IOStreams.Out)
+	for _, summary := range releases {
+		err := encoder.Encode(summaryEntry{
+			Release:    summary.name,
+			Namespace:  summary.namespace,
+			Revisions:  summary.revisions,
+			Superseded: summary.superseded,
+			Prune:      summary.superseded > maxSuperseded,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunSummary(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+
+	objects := []runtime.Object{
+		releaseSecret(t, "payments", "api", 12, `{"name":"api","version":12,"info":{"status":"deployed"}}`),
+		releaseSecret(t, "payments", "worker", 1, `{"name":"worker","version":1,"info":{"status":"failed"}}`),
+		releaseSecret(t, "search", "api", 1, `{"name":"api","version":1,"info":{"status":"pending-install"}}`),
+		releaseSecret(t, "search", "old", 1, `{"name":"old","version":1,"info":{"status":"uninstalled"}}`),
+	}
+	for version := 1; version < 12; version++ {
+		objects = append(objects, releaseSecret(t, "payments", "api", version, fmt.Sprintf(`{"name":"api","version":%d,"info":{"status":"superseded"}}`, version)))
+	}
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:     genericclioptions.IOStreams{Out: out},
+		kubeclient:    fake.NewSimpleClientset(objects...),
+		allNamespaces: true,
+		summary:       true,
+	}
+	require.NoError(t, modify.Run())
+
+	expected := `STATUS       REVISIONS
+deployed     1
+superseded   11
+failed       1
+pending      1
+uninstalled  1
+
+RELEASE  NAMESPACE  REVISIONS  SUPERSEDED  NOTE
+api      payments   12         11          prune: more than 10 superseded revisions
+worker   payments   1          0           
+api      search     1          0           
+old      search     1          0           
+`
+	assert.Equal(t, expected, out.String())
+
+	out.Reset()
+	modify.output = "jsonl"
+	require.NoError(t, modify.Run())
+	expected = `{"release":"api","namespace":"payments","revisions":12,"superseded":11,"prune":true}
+{"release":"worker","namespace":"payments","revisions":1,"superseded":0,"prune":false}
+{"release":"api","namespace":"search","revisions":1,"superseded":0,"prune":false}
+{"release":"old","namespace":"search","revisions":1,"superseded":0,"prune":false}
+`
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// templateErrorLine finds the line number in the errors of text/template
+var templateErrorLine = regexp.MustCompile(`^template: [^:]*:(\d+):`)
+
+// parseOutputTemplate parses the Go template of --output-template, a parse error quotes the offending line
+func parseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err == nil {
+		return tmpl, nil
+	}
+
+	match := templateErrorLine.FindStringSubmatch(err.Error())
+	if match == nil {
+		return nil, fmt.Errorf("invalid --output-template: %v", err)
+	}
+
+	lines := strings.Split(text, "\n")
+	line, _ := strconv.Atoi(match[1])
+	if line < 1 || line > len(lines) {
+		return nil, fmt.Errorf("invalid --output-template: %v", err)
+	}
+
+	return nil, fmt.Errorf("invalid --output-template: %v, in line %d: %q", err, line, lines[line-1])
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunListOutputTemplate(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "myapp", 2, `{"name":"myapp","version":2,"info":{"status":"deployed"},"chart":{"metadata":{"version":"1.1.0"}}}`),
+		releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"superseded"},"chart":{"metadata":{"version":"1.0.0"}}}`),
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:      genericclioptions.IOStreams{Out: out},
+		kubeclient:     client,
+		namespace:      namespace,
+		list:           true,
+		outputTemplate: "{{.Name}} {{.Version}} {{.Status}} {{.Chart.Metadata.Version}}",
+	}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, "myapp 1 superseded 1.0.0\nmyapp 2 deployed 1.1.0\n", out.String())
+}
+
+func TestParseOutputTemplate(t *testing.T) {
+	_, err := parseOutputTemplate("{{.Name}}\n{{.Status")
+	assert.EqualError(t, err, `invalid --output-template: template: output:2: unclosed action, in line 2: "{{.Status"`)
+
+	_, err = parseOutputTemplate("{{.Name}} {{end}}")
+	assert.EqualError(t, err, `invalid --output-template: template: output:1: unexpected {{end}}, in line 1: "{{.Name}} {{end}}"`)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// secretNameByUID returns the name of the secret of the namespace having the UID given with --uid
+func (o *ModifySecretOptions) secretNameByUID() (string, error) {
+	items, err := o.driver.List(context.TODO(), o.namespace, "", "")
+	if err != nil {
+		return "", err
+	}
+
+	for _, secret := range items {
+		if secret.UID == types.UID(o.uid) {
+			return secret.Name, nil
+		}
+	}
+
+	return "", &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotFound,
+		Reason:  metav1.StatusReasonNotFound,
+		Message: fmt.Sprintf("no secret with UID %q in namespace %q", o.uid, o.namespace),
+	}}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunByUID(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	client := fake.NewSimpleClientset(
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: namespace, UID: "1111"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: namespace, UID: "2222"}},
+	)
+
+	modify := ModifySecretOptions{
+		kubeclient: client,
+		namespace:  namespace,
+		uid:        "2222",
+		literals:   map[string]string{"password": "newpass"},
+	}
+	require.NoError(t, modify.Run())
+
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), "second", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "newpass", string(secret.Data["password"]))
+
+	secret, err = client.CoreV1().Secrets(namespace).Get(context.TODO(), "first", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, secret.Data)
+
+	modify.uid = "3333"
+	err = modify.Run()
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.EqualError(t, err, `no secret with UID "3333" in namespace "mynamespace"`)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latestReleaseURL is the GitHub API endpoint describing the latest release of the plugin
+var latestReleaseURL = "https://api.github.com/repos/ArnaudTA/kubectl-modify-helm/releases/latest"
+
+// githubRelease is the subset of a GitHub release we care about
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// checkUpdate prints whether a newer version of the plugin has been released.
+// Failing to reach GitHub is not an error, the current version is printed instead.
+func checkUpdate(out io.Writer, url string) error {
+	fmt.Fprintf(out, "current version: %s\n", Version)
+
+	latest, err := fetchLatestRelease(url)
+	if err != nil {
+		fmt.Fprintf(out, "couldn't check for updates: %v\n", err)
+		return nil
+	}
+
+	if compareVersions(latest.TagName, Version) > 0 {
+		fmt.Fprintf(out, "update available: %s, download it from %s\n", latest.TagName, latest.HTMLURL)
+		return nil
+	}
+
+	fmt.Fprintln(out, "you are running the latest version")
+	return nil
+}
+
+// fetchLatestRelease queries GitHub for the latest release
+func fetchLatestRelease(url string) (*githubRelease, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	latest := &githubRelease{}
+	err = json.NewDecoder(resp.Body).Decode(latest)
+	if err != nil {
+		return nil, err
+	}
+
+	return latest, nil
+}
+
+// compareVersions compares two dotted versions, ignoring a leading "v".
+// Versions that are not numeric are considered older than any numeric one.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		na, nb := versionPart(pa, i), versionPart(pb, i)
+		if na != nb {
+			if na > nb {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	return 0
+}
+
+// versionPart returns the numeric value of the i-th part of a version, -1 if it is not a number
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+
+	n, err := strconv.Atoi(strings.SplitN(parts[i], "-", 2)[0])
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
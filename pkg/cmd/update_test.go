@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUpdate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name":"v1.2.0","html_url":"https://example.com/releases/v1.2.0"}`)
+	}))
+	defer server.Close()
+
+	origVersion := Version
+	defer func() { Version = origVersion }()
+
+	testcases := []struct {
+		name     string
+		version  string
+		url      string
+		expected string
+	}{
+		{
+			name:     "update available",
+			version:  "v1.1.3",
+			url:      server.URL,
+			expected: "update available: v1.2.0, download it from https://example.com/releases/v1.2.0",
+		},
+		{
+			name:     "latest version",
+			version:  "1.2.0",
+			url:      server.URL,
+			expected: "you are running the latest version",
+		},
+		{
+			name:     "unknown version",
+			version:  "unknown",
+			url:      server.URL,
+			expected: "update available: v1.2.0",
+		},
+		{
+			name:     "offline",
+			version:  "v1.1.3",
+			url:      "http://127.0.0.1:1",
+			expected: "couldn't check for updates",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			Version = tc.version
+			out := &bytes.Buffer{}
+			require.NoError(t, checkUpdate(out, tc.url))
+			assert.Contains(t, out.String(), "current version: "+tc.version)
+			assert.Contains(t, out.String(), tc.expected)
+		})
+	}
+}
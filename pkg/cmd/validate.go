@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// runValidateAll decodes and parses every Helm release in the namespaces to operate in, along with the documents
+// of its manifest, and reports the corrupt ones
+func (o *ModifySecretOptions) runValidateAll() error {
+	items, err := o.releaseSecrets()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SECRET\tNAMESPACE\tRESULT")
+	corrupt := 0
+	for _, secret := range items {
+		rel, err := o.parsedRelease(&secret)
+		if err == nil {
+			_, err = release.SplitManifest(rel.Manifest)
+			if err != nil {
+				corrupt++
+				fmt.Fprintf(w, "%s\t%s\tcorrupt (manifest): %v\n", secret.Name, secret.Namespace, err)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\tok\n", secret.Name, secret.Namespace)
+			continue
+		}
+
+		corrupt++
+		var decodeErr *release.DecodeError
+		if errors.As(err, &decodeErr) {
+			fmt.Fprintf(w, "%s\t%s\tcorrupt (%s): %v\n", secret.Name, secret.Namespace, decodeErr.Step, decodeErr.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\tcorrupt: %v\n", secret.Name, secret.Namespace, err)
+	}
+	w.Flush()
+
+	if corrupt > 0 {
+		return fmt.Errorf("%w: %d of %d releases are corrupt", release.ErrDecode, corrupt, len(items))
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunValidateAll(t *testing.T) {
+	const namespace = "mynamespace"
+
+	badBase64 := releaseSecret(t, namespace, "api", 1, "")
+	badBase64.Data["release"] = []byte("not base64!")
+	badJSON := releaseSecret(t, namespace, "web", 1, "{not json")
+	badManifest := releaseSecret(t, namespace, "web", 2, `{"name":"web","version":2,"manifest":"---\nkind: Service\n---\nkind: [Deployment\n"}`)
+
+	client := fake.NewSimpleClientset(
+		releaseSecret(t, namespace, "api", 2, `{"name":"api","version":2,"manifest":"---\nkind: Service\n"}`),
+		badBase64,
+		badJSON,
+		badManifest,
+	)
+
+	out := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:   genericclioptions.IOStreams{Out: out},
+		kubeclient:  client,
+		namespace:   namespace,
+		validateAll: true,
+	}
+	err := modify.Run()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, release.ErrDecode))
+	assert.Contains(t, err.Error(), "3 of 4 releases are corrupt")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 5)
+	assert.Contains(t, string(lines[1]), "sh.helm.release.v1.api.v1  mynamespace  corrupt (base64)")
+	assert.Contains(t, string(lines[2]), "sh.helm.release.v1.api.v2  mynamespace  ok")
+	assert.Contains(t, string(lines[3]), "sh.helm.release.v1.web.v1  mynamespace  corrupt (json)")
+	assert.Contains(t, string(lines[4]), "sh.helm.release.v1.web.v2  mynamespace  corrupt (manifest): document 2 of the manifest is invalid")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+)
+
+// waitInterval is the interval --wait polls the secret at
+var waitInterval = time.Second
+
+// waitForStatus polls the secret after it was updated until its release reaches the status of --for-status,
+// for instance once a controller post-processed it, or until the timeout of --timeout elapses
+func (o *ModifySecretOptions) waitForStatus(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.waitTimeout)
+	defer cancel()
+
+	opts := o.modifyOptions()
+	status := ""
+	for {
+		secret, err := secrets.Fresh(o.driver).Get(ctx, name, o.namespace)
+		var key string
+		if err == nil {
+			key, err = opts.ReleaseKeyOf(secret)
+		}
+		if err == nil {
+			var rel *release.Release
+			rel, err = release.Parse(secret.Data[key])
+			if err == nil {
+				status = rel.Info.Status
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			logrus.Debugf("waiting for secret %q: %v", name, err)
+		}
+
+		if status == o.forStatus {
+			logrus.Infof("release of secret %q is %s", name, status)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for the release of secret %q to be %s, it is %q", o.waitTimeout, name, o.forStatus, status)
+		case <-time.After(waitInterval):
+		}
+	}
+}
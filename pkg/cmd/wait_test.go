@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWaitForStatus(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+	defer func(interval time.Duration) { waitInterval = interval }(waitInterval)
+	waitInterval = time.Millisecond
+
+	pending := releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"pending-upgrade"}}`)
+	deployed := releaseSecret(t, namespace, "myapp", 1, `{"name":"myapp","version":1,"info":{"status":"deployed"}}`)
+	client := fake.NewSimpleClientset(pending)
+	gets := 0
+	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gets++
+		if gets < 3 {
+			return false, nil, nil
+		}
+		return true, deployed, nil
+	})
+
+	modify := ModifySecretOptions{
+		driver:      &secrets.SecretDriver{Client: client},
+		namespace:   namespace,
+		forStatus:   "deployed",
+		waitTimeout: time.Minute,
+	}
+	require.NoError(t, modify.waitForStatus(pending.Name))
+	assert.Equal(t, 3, gets)
+
+	// the release is found in the only base64+gzip key when the configured one is missing
+	deployed.Data = map[string][]byte{"payload": deployed.Data["release"], "token": []byte("s3cr3t")}
+	modify.releaseKey = "release"
+	modify.waitTimeout = time.Second
+	require.NoError(t, modify.waitForStatus(pending.Name))
+
+	modify.forStatus = "failed"
+	modify.waitTimeout = 20 * time.Millisecond
+	assert.EqualError(t, modify.waitForStatus(pending.Name), `timed out after 20ms waiting for the release of secret "sh.helm.release.v1.myapp.v1" to be failed, it is "deployed"`)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/editor"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/modify"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// defaultMergeTool is used to merge concurrent changes when --merge-tool is not set
+const defaultMergeTool = "vimdiff"
+
+// watchSecret watches the secret for changes made on the server while it is being edited.
+// The returned function stops watching and returns the latest version of the secret if it changed, nil otherwise.
+func (o *ModifySecretOptions) watchSecret(secret *v1.Secret) (func() *v1.Secret, error) {
+	w, err := o.kubeclient.CoreV1().Secrets(secret.Namespace).Watch(context.TODO(), metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", secret.Name).String(),
+		ResourceVersion: secret.ResourceVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var latest *v1.Secret
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range w.ResultChan() {
+			changed, ok := event.Object.(*v1.Secret)
+			if event.Type != watch.Modified || !ok || changed.ResourceVersion == secret.ResourceVersion {
+				continue
+			}
+
+			mu.Lock()
+			latest = changed
+			mu.Unlock()
+		}
+	}()
+
+	return func() *v1.Secret {
+		w.Stop()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+		return latest
+	}, nil
+}
+
+// resolveConcurrentChange asks the user what to do with an edit of a secret which changed on the server meanwhile:
+// apply it over the new version, merge it with the new version, or abort.
+// The secret is replaced with the new version when the edit is to be applied.
+func (o *ModifySecretOptions) resolveConcurrentChange(secret, latest *v1.Secret, editedFile string) error {
+	logrus.Warnf("the release changed on the server while you were editing")
+
+	answer, _ := o.prompt("apply your edit over the server version? [y]es, [m]erge, [N]o: ")
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+	case "m", "merge":
+		err := o.mergeWithServer(latest, editedFile)
+		if err != nil {
+			return err
+		}
+	default:
+		edited, err := os.ReadFile(editedFile)
+		if err == nil {
+			o.saveEdits(edited)
+		}
+		return fmt.Errorf("edit of secret %q aborted, it changed on the server", o.secretName)
+	}
+
+	*secret = *latest
+	return nil
+}
+
+// mergeWithServer opens the merge tool on the edited file and the version of the release on the server
+func (o *ModifySecretOptions) mergeWithServer(latest *v1.Secret, editedFile string) error {
+	content, _, err := o.releaseOf(latest)
+	if err != nil {
+		return err
+	}
+
+	opts := o.modifyOptions()
+	buffer, err := opts.Render(content)
+	if err != nil {
+		return err
+	}
+
+	server, err := os.CreateTemp("", fmt.Sprintf("%s-%s-server-*%s", o.namespace, o.secretName, filepath.Ext(editedFile)))
+	if err != nil {
+		return err
+	}
+	server.Close()
+	defer modify.RemoveFile(server.Name())
+
+	err = os.WriteFile(server.Name(), buffer, 0600)
+	if err != nil {
+		return err
+	}
+
+	tool := o.mergeTool
+	if tool == "" {
+		tool = defaultMergeTool
+	}
+
+	return editor.Merge(tool, editedFile, server.Name())
+}
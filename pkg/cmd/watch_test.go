@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var secretsResource = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+func TestWatchCluster(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("EDITOR", "sed -i= s/v1/v2/")
+
+	testcases := []struct {
+		name     string
+		answer   string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "apply anyway",
+			answer:   "y\n",
+			expected: `{"config":{"tag":"v2"},"manifest":"changed"}`,
+		},
+		{
+			name:     "abort",
+			answer:   "\n",
+			expected: `{"config":{"tag":"v1"},"manifest":"changed"}`,
+			err:      true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "1"},
+				Data:       map[string][]byte{"release": encodeRelease(t, `{"config":{"tag":"v1"},"manifest":"original"}`)},
+			})
+
+			changed := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: "2"},
+				Data:       map[string][]byte{"release": encodeRelease(t, `{"config":{"tag":"v1"},"manifest":"changed"}`)},
+			}
+			client.PrependWatchReactor("secrets", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				require.NoError(t, client.Tracker().Update(secretsResource, changed, namespace))
+
+				w := watch.NewFakeWithChanSize(1, false)
+				w.Modify(changed)
+				return true, w, nil
+			})
+
+			errOut := &bytes.Buffer{}
+			modify := ModifySecretOptions{
+				IOStreams:    genericclioptions.IOStreams{In: strings.NewReader(tc.answer), Out: ioutil.Discard, ErrOut: errOut},
+				kubeclient:   client,
+				secretName:   name,
+				namespace:    namespace,
+				valuesOnly:   true,
+				watchCluster: true,
+			}
+			err := modify.Run()
+			if tc.err {
+				require.Error(t, err)
+				files, err := os.ReadDir(recoveryDir())
+				require.NoError(t, err)
+				assert.NotEmpty(t, files)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Contains(t, errOut.String(), "[y]es, [m]erge, [N]o")
+
+			secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, decodeRelease(t, secret.Data["release"]))
+		})
+	}
+}
+
+func TestWatchClusterUnchanged(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	t.Setenv("EDITOR", "sed -i= s/v1/v2/")
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encodeRelease(t, `{"config":{"tag":"v1"}}`)},
+	})
+	client.PrependWatchReactor("secrets", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, watch.NewFake(), nil
+	})
+
+	errOut := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:    genericclioptions.IOStreams{In: strings.NewReader(""), Out: ioutil.Discard, ErrOut: errOut},
+		kubeclient:   client,
+		secretName:   "mysecret",
+		namespace:    "mynamespace",
+		watchCluster: true,
+	}
+	require.NoError(t, modify.Run())
+	assert.Empty(t, errOut.String())
+}
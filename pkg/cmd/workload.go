@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// secretNameFromDeployment returns the name of the secret to edit among the ones referenced by the
+// deployment given with --from-deployment, asking the user to pick one when there are several
+func (o *ModifySecretOptions) secretNameFromDeployment() (string, error) {
+	deployment, err := o.kubeclient.AppsV1().Deployments(o.namespace).Get(context.TODO(), o.fromDeployment, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	names := podSecrets(deployment.Spec.Template.Spec)
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("deployment %q in namespace %q references no secrets", o.fromDeployment, o.namespace)
+	case 1:
+		logrus.Infof("editing secret %q, the only one referenced by deployment %q", names[0], o.fromDeployment)
+		return names[0], nil
+	}
+
+	return o.chooseSecret(names)
+}
+
+// podSecrets returns the sorted names of the secrets the pod references in the environment of its
+// containers, with envFrom or secretKeyRef, and in its volumes
+func podSecrets(spec v1.PodSpec) []string {
+	found := map[string]bool{}
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, source := range container.EnvFrom {
+			if source.SecretRef != nil {
+				found[source.SecretRef.Name] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				found[env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			found[volume.Secret.SecretName] = true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					found[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// chooseSecret presents a numbered menu of the secrets on stderr and returns the one chosen
+func (o *ModifySecretOptions) chooseSecret(names []string) (string, error) {
+	for i, name := range names {
+		fmt.Fprintf(o.IOStreams.ErrOut, "%d) %s\n", i+1, name)
+	}
+
+	answer, err := o.prompt(fmt.Sprintf("secret of deployment %q to edit: ", o.fromDeployment))
+	if err != nil {
+		return "", fmt.Errorf("no secret picked: %v", err)
+	}
+
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > len(names) {
+		return "", fmt.Errorf("invalid choice %q, expected a number between 1 and %d", answer, len(names))
+	}
+
+	return names[choice-1], nil
+}
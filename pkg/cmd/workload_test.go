@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodSecrets(t *testing.T) {
+	spec := v1.PodSpec{
+		InitContainers: []v1.Container{{
+			EnvFrom: []v1.EnvFromSource{{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "init-env"}}}},
+		}},
+		Containers: []v1.Container{{
+			EnvFrom: []v1.EnvFromSource{{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "config"}}}},
+			Env: []v1.EnvVar{
+				{Name: "PASSWORD", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "db"}, Key: "password"}}},
+				{Name: "USER", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "db"}, Key: "user"}}},
+				{Name: "MODE", Value: "prod"},
+			},
+		}},
+		Volumes: []v1.Volume{
+			{Name: "tls", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "tls"}}},
+			{Name: "all", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+				{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "projected"}}},
+			}}}},
+		},
+	}
+
+	assert.Equal(t, []string{"db", "init-env", "projected", "tls"}, podSecrets(spec))
+}
+
+func TestRunFromDeployment(t *testing.T) {
+	const namespace = "mynamespace"
+
+	logrus.SetOutput(ioutil.Discard)
+
+	deployment := func(name string, volumes ...string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		for _, volume := range volumes {
+			d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, v1.Volume{Name: volume, VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: volume}}})
+		}
+		return d
+	}
+
+	client := fake.NewSimpleClientset(
+		deployment("single", "first"),
+		deployment("several", "first", "second"),
+		deployment("none"),
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: namespace}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: namespace}},
+	)
+
+	errOut := &bytes.Buffer{}
+	modify := ModifySecretOptions{
+		IOStreams:      genericclioptions.IOStreams{In: strings.NewReader("2\n"), ErrOut: errOut},
+		kubeclient:     client,
+		namespace:      namespace,
+		fromDeployment: "single",
+		literals:       map[string]string{"password": "first"},
+	}
+	require.NoError(t, modify.Run())
+	assert.Empty(t, errOut.String())
+
+	modify.fromDeployment = "several"
+	modify.literals = map[string]string{"password": "second"}
+	require.NoError(t, modify.Run())
+	assert.Equal(t, "1) first\n2) second\nsecret of deployment \"several\" to edit: ", errOut.String())
+
+	for _, name := range []string{"first", "second"} {
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, name, string(secret.Data["password"]))
+	}
+
+	modify.fromDeployment = "none"
+	assert.EqualError(t, modify.Run(), `deployment "none" in namespace "mynamespace" references no secrets`)
+}
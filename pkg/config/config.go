@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config holds the settings read from the configuration file of the plugin
+type Config struct {
+	// ConfirmName lists namespace patterns, with filepath.Match semantics, in which
+	// the secret name must be typed to confirm an edit
+	ConfirmName []string `json:"confirmName"`
+}
+
+// DefaultPath returns the path of the configuration file, $KUBECTL_MODIFY_SECRET_CONFIG
+// or kubectl-modify-secret/config.yaml in the user configuration directory
+func DefaultPath() string {
+	if path := os.Getenv("KUBECTL_MODIFY_SECRET_CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "kubectl-modify-secret", "config.yaml")
+}
+
+// Load reads the configuration file, a missing file gives an empty configuration
+func Load(path string) (*Config, error) {
+	config := &Config{}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.UnmarshalStrict(data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// RequiresConfirmName reports whether edits in the namespace must be confirmed by typing the secret name
+func (c *Config) RequiresConfirmName(namespace string) bool {
+	for _, pattern := range c.ConfirmName {
+		if ok, _ := filepath.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	config, err := Load(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, config)
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("confirmName:\n- prod*\n- kube-system\n"), 0600))
+	config, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod*", "kube-system"}, config.ConfirmName)
+
+	require.NoError(t, os.WriteFile(path, []byte("confirm: [prod*]\n"), 0600))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
+
+func TestRequiresConfirmName(t *testing.T) {
+	config := &Config{ConfirmName: []string{"prod*", "kube-system"}}
+
+	assert.True(t, config.RequiresConfirmName("production"))
+	assert.True(t, config.RequiresConfirmName("prod-eu"))
+	assert.True(t, config.RequiresConfirmName("kube-system"))
+	assert.False(t, config.RequiresConfirmName("staging"))
+	assert.False(t, (&Config{}).RequiresConfirmName("prod"))
+}
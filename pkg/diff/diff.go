@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// noNewline marks a last line without a newline, like diff does
+const noNewline = "\n\\ No newline at end of file\n"
+
+// ANSI escape codes of the colored diff
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// Options configures a unified diff
+type Options struct {
+	// FromFile and ToFile name the contents in the header of the diff
+	FromFile string
+	ToFile   string
+	// Context is the number of unchanged lines shown around each change
+	Context int
+	// Color highlights the diff with ANSI escape codes, for terminals
+	Color bool
+}
+
+// Unified returns the unified diff between a and b, empty when they are equal
+func Unified(a, b []byte, opts Options) string {
+	// writing to the in memory buffer of GetUnifiedDiffString doesn't fail
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(a)),
+		B:        splitLines(string(b)),
+		FromFile: opts.FromFile,
+		ToFile:   opts.ToFile,
+		Context:  opts.Context,
+	})
+
+	if !opts.Color {
+		return diff
+	}
+
+	return colorize(diff)
+}
+
+// splitLines splits the content in lines keeping their newline, a missing newline at the end is marked
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+
+	lines[len(lines)-1] += noNewline
+	return lines
+}
+
+// colorize highlights the headers, hunk ranges, deletions and additions of the diff
+func colorize(diff string) string {
+	var b strings.Builder
+	inHunk := false
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(text, "@@"):
+			inHunk = true
+			color = colorCyan
+		case !inHunk:
+			// the file names preceding the first hunk
+			color = colorBold
+		case strings.HasPrefix(text, "-"):
+			color = colorRed
+		case strings.HasPrefix(text, "+"):
+			color = colorGreen
+		}
+
+		if color == "" || text == "" {
+			b.WriteString(line)
+			continue
+		}
+		b.WriteString(color + text + colorReset + line[len(text):])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	opts := Options{FromFile: "live", ToFile: "edited", Context: 1}
+
+	testcases := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{
+			name:     "no change",
+			a:        "a: 1\nb: 2\n",
+			b:        "a: 1\nb: 2\n",
+			expected: "",
+		},
+		{
+			name:     "addition",
+			a:        "a: 1\nb: 2\n",
+			b:        "a: 1\nb: 2\nc: 3\n",
+			expected: "--- live\n+++ edited\n@@ -2 +2,2 @@\n b: 2\n+c: 3\n",
+		},
+		{
+			name:     "deletion",
+			a:        "a: 1\nb: 2\nc: 3\n",
+			b:        "a: 1\nc: 3\n",
+			expected: "--- live\n+++ edited\n@@ -1,3 +1,2 @@\n a: 1\n-b: 2\n c: 3\n",
+		},
+		{
+			name:     "from empty",
+			a:        "",
+			b:        "a: 1\n",
+			expected: "--- live\n+++ edited\n@@ -0,0 +1 @@\n+a: 1\n",
+		},
+		{
+			name:     "missing final newline",
+			a:        "a: 1\nb: 2",
+			b:        "a: 1\nb: 2\n",
+			expected: "--- live\n+++ edited\n@@ -1,2 +1,2 @@\n a: 1\n-b: 2\n\\ No newline at end of file\n+b: 2\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Unified([]byte(tc.a), []byte(tc.b), opts))
+		})
+	}
+}
+
+func TestUnifiedColor(t *testing.T) {
+	diff := Unified([]byte("---\na: 1\n"), []byte("a: 2\n"), Options{FromFile: "live", ToFile: "edited", Color: true})
+	expected := "\x1b[1m--- live\x1b[0m\n" +
+		"\x1b[1m+++ edited\x1b[0m\n" +
+		"\x1b[36m@@ -1,2 +1 @@\x1b[0m\n" +
+		"\x1b[31m----\x1b[0m\n" +
+		"\x1b[31m-a: 1\x1b[0m\n" +
+		"\x1b[32m+a: 2\x1b[0m\n"
+	assert.Equal(t, expected, diff)
+}
@@ -25,6 +25,17 @@ func Edit(file string) error {
 
 	command, args := getCommandAndArgs(getEditor(), file)
 
+	return run(command, args)
+}
+
+// Merge opens the merge tool on the file, along with the base it should be reconciled with when given
+func Merge(tool, file, base string) error {
+	command, args := getMergeCommandAndArgs(tool, file, base)
+
+	return run(command, args)
+}
+
+func run(command string, args []string) error {
 	cmd := exec.Command(command, args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -41,3 +52,12 @@ func getCommandAndArgs(editorFromEnv, file string) (string, []string) {
 
 	return command, []string{file}
 }
+
+func getMergeCommandAndArgs(tool, file, base string) (string, []string) {
+	command, args := getCommandAndArgs(tool, file)
+	if base != "" {
+		args = append(args, base)
+	}
+
+	return command, args
+}
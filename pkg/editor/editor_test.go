@@ -52,3 +52,46 @@ func TestGetCommandAndArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMergeCommandAndArgs(t *testing.T) {
+	testcases := []struct {
+		name            string
+		tool            string
+		file            string
+		base            string
+		expectedCommand string
+		expectedArgs    []string
+	}{
+		{
+			name:            "merge tool without base",
+			tool:            "vimdiff",
+			file:            "some-file.txt",
+			expectedCommand: "vimdiff",
+			expectedArgs:    []string{"some-file.txt"},
+		},
+		{
+			name:            "merge tool with base",
+			tool:            "vimdiff",
+			file:            "some-file.txt",
+			base:            "base-file.txt",
+			expectedCommand: "vimdiff",
+			expectedArgs:    []string{"some-file.txt", "base-file.txt"},
+		},
+		{
+			name:            "merge tool with arguments and base",
+			tool:            "meld --newtab",
+			file:            "some-file.txt",
+			base:            "base-file.txt",
+			expectedCommand: "meld",
+			expectedArgs:    []string{"--newtab", "some-file.txt", "base-file.txt"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, args := getMergeCommandAndArgs(tc.tool, tc.file, tc.base)
+			assert.Equal(t, tc.expectedCommand, command)
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package editor
+
+import (
+	"os"
+	"os/exec"
+
+	"sigs.k8s.io/yaml"
+)
+
+// sopsCommand is the SOPS binary used to decrypt and re-encrypt files
+const sopsCommand = "sops"
+
+// IsSOPS tells whether the YAML or JSON content is a SOPS encrypted document,
+// recognized by the sops metadata holding its message authentication code
+func IsSOPS(content []byte) bool {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return false
+	}
+
+	metadata, ok := document["sops"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, ok = metadata["mac"]
+	return ok
+}
+
+// EditSOPS opens the editor on the decrypted SOPS file through sops, which re-encrypts it with the same keys on save
+func EditSOPS(file string) error {
+	editor := getEditor()
+
+	cmd := exec.Command(sopsCommand, file)
+	cmd.Env = append(os.Environ(), "EDITOR="+editor, "SOPS_EDITOR="+editor)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
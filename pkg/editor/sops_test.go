@@ -0,0 +1,44 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSOPS(t *testing.T) {
+	testcases := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{
+			name:     "sops yaml",
+			content:  "password: ENC[AES256_GCM,data:abc,type:str]\nsops:\n  mac: ENC[AES256_GCM,data:def,type:str]\n  version: 3.8.1\n",
+			expected: true,
+		},
+		{
+			name:     "sops json",
+			content:  `{"password":"ENC[AES256_GCM,data:abc,type:str]","sops":{"mac":"ENC[AES256_GCM,data:def,type:str]"}}`,
+			expected: true,
+		},
+		{
+			name:    "plain values",
+			content: "password: secret\n",
+		},
+		{
+			name:    "sops key without metadata",
+			content: "sops: enabled\n",
+		},
+		{
+			name:    "not a document",
+			content: "- a\n- b\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsSOPS([]byte(tc.content)))
+		})
+	}
+}
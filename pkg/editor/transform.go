@@ -0,0 +1,30 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Transform pipes the content to the program, split on spaces like $EDITOR, and returns what it prints.
+// Its stderr is passed through, and the edit fails when it exits with an error.
+func Transform(program string, content []byte) ([]byte, error) {
+	fields := strings.Fields(program)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no transform program")
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("transform %q failed: %v", program, err)
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,20 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	out, err := Transform("sed s/v1/v2/", []byte("tag: v1\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "tag: v2\n", string(out))
+
+	_, err = Transform("false", []byte("tag: v1\n"))
+	assert.EqualError(t, err, `transform "false" failed: exit status 1`)
+
+	_, err = Transform(" ", nil)
+	assert.EqualError(t, err, "no transform program")
+}
@@ -0,0 +1,44 @@
+package modify
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RemoveFile overwrites the file with zeros before removing it, so no plaintext is left on disk
+func RemoveFile(file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(file, make([]byte, info.Size()), 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(file)
+}
+
+// removeFileOnSignal removes the file and exits when the plugin is interrupted or terminated.
+// The returned function stops watching for signals.
+func removeFileOnSignal(file string) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			RemoveFile(file)
+			os.Exit(128 + int(sig.(syscall.Signal)))
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,27 @@
+package modify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "release.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("password: s3cr3t"), 0600))
+
+	require.NoError(t, RemoveFile(file))
+	assert.NoFileExists(t, file)
+}
+
+func TestRemoveFileOnSignalStop(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "release.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("password: s3cr3t"), 0600))
+
+	stop := removeFileOnSignal(file)
+	stop()
+	assert.FileExists(t, file)
+}
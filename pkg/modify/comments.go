@@ -0,0 +1,78 @@
+package modify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrEmptyEdit is returned when the file the release is edited in is saved empty, or with only comments,
+// which cancels the edit
+var ErrEmptyEdit = errors.New("edit cancelled, no changes applied")
+
+// commented tells whether guidance comments are prepended to the content in the editor.
+// Chart files and notes are edited as is, a leading # may be part of them.
+func (opts Options) commented() bool {
+	return !opts.NoComments && opts.ChartFile == "" && !opts.NotesOnly
+}
+
+// editComments returns the comment lines prepended to the content in the editor, like kubectl edit does.
+// With ShowComputed, the computed values of the decoded release follow the guidance.
+func (opts Options) editComments(decoded []byte) []byte {
+	what := "Helm release"
+	if opts.ValuesOnly {
+		what = "values of the Helm release"
+	}
+
+	comments := []byte(fmt.Sprintf(`# Please edit the %s below, in %s. It is shown decoded and is encoded back when saved.
+# Lines beginning with a '#' at the top of the file will be ignored,
+# and an empty file will cancel the edit.
+#
+`, what, opts.format()))
+
+	if opts.ShowComputed {
+		comments = append(comments, computedComments(decoded)...)
+	}
+
+	return comments
+}
+
+// computedComments returns the values of the chart merged with the values of the release as comment lines,
+// so overrides aren't edited blind to the defaults
+func computedComments(decoded []byte) []byte {
+	values, err := release.EffectiveValues(decoded)
+	if err == nil {
+		values, err = yaml.JSONToYAML(values)
+	}
+	if err != nil {
+		logrus.Warnf("failed to compute the values of the release, they are not shown: %v", err)
+		return nil
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Computed values, the chart defaults merged with the values of the release, read-only:\n#\n")
+	for _, line := range strings.Split(strings.TrimSuffix(string(values), "\n"), "\n") {
+		b.WriteString(strings.TrimRight("#   "+line, " ") + "\n")
+	}
+	b.WriteString("#\n")
+
+	return b.Bytes()
+}
+
+// stripComments removes the comment lines at the top of the edited content
+func stripComments(content []byte) []byte {
+	for bytes.HasPrefix(content, []byte("#")) {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			return nil
+		}
+		content = content[end+1:]
+	}
+
+	return content
+}
@@ -0,0 +1,140 @@
+package modify
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestStripComments(t *testing.T) {
+	assert.Equal(t, "name: myapp\n# kept\n", string(stripComments([]byte("# one\n#\n# two\nname: myapp\n# kept\n"))))
+	assert.Equal(t, "name: myapp\n", string(stripComments([]byte("name: myapp\n"))))
+	assert.Empty(t, stripComments([]byte("# one\n# two")))
+}
+
+func TestRunComments(t *testing.T) {
+	driver := newDriver(t, `{"name":"value"}`)
+
+	var presented string
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit: func(file string, secret *v1.Secret) error {
+			content, err := os.ReadFile(file)
+			presented = string(content)
+			if err != nil {
+				return err
+			}
+			return replace("value", "updated")(file, secret)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `# Please edit the Helm release below, in yaml. It is shown decoded and is encoded back when saved.
+# Lines beginning with a '#' at the top of the file will be ignored,
+# and an empty file will cancel the edit.
+#
+name: value
+`, presented)
+	assert.Equal(t, "name: updated\n", string(result.After))
+	assert.Equal(t, `{"name":"updated"}`, storedRelease(t, driver))
+
+	_, err = Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit: func(file string, secret *v1.Secret) error {
+			return os.WriteFile(file, []byte("# only comments\n\n"), 0600)
+		},
+	})
+	assert.ErrorIs(t, err, ErrEmptyEdit)
+	assert.Equal(t, `{"name":"updated"}`, storedRelease(t, driver))
+
+	_, err = Run(context.TODO(), nil, Options{
+		Name:       "mysecret",
+		Namespace:  "mynamespace",
+		Driver:     driver,
+		NoComments: true,
+		Edit: func(file string, secret *v1.Secret) error {
+			content, err := os.ReadFile(file)
+			presented = string(content)
+			return err
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "name: updated\n", presented)
+}
+
+func TestRunEmptyFile(t *testing.T) {
+	testcases := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "without comments",
+			opts: Options{NoComments: true},
+		},
+		{
+			name: "notes",
+			opts: Options{NotesOnly: true},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newDriver(t, `{"name":"value","info":{"notes":"Visit http://myapp"}}`)
+
+			opts := tc.opts
+			opts.Name = "mysecret"
+			opts.Namespace = "mynamespace"
+			opts.Driver = driver
+			opts.Edit = func(file string, secret *v1.Secret) error {
+				return os.WriteFile(file, []byte(" \n\n"), 0600)
+			}
+			result, err := Run(context.TODO(), nil, opts)
+			assert.EqualError(t, err, "edit cancelled, no changes applied")
+			assert.False(t, result.Changed)
+			assert.Equal(t, `{"name":"value","info":{"notes":"Visit http://myapp"}}`, storedRelease(t, driver))
+		})
+	}
+}
+
+func TestRunShowComputed(t *testing.T) {
+	driver := newDriver(t, `{"name":"myapp","chart":{"values":{"replicas":1,"image":{"tag":"v1"}}},"config":{"replicas":3}}`)
+
+	var presented string
+	result, err := Run(context.TODO(), nil, Options{
+		Name:         "mysecret",
+		Namespace:    "mynamespace",
+		Driver:       driver,
+		ValuesOnly:   true,
+		ShowComputed: true,
+		Edit: func(file string, secret *v1.Secret) error {
+			content, err := os.ReadFile(file)
+			presented = string(content)
+			if err != nil {
+				return err
+			}
+			return replace("replicas: 3", "replicas: 5")(file, secret)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `# Please edit the values of the Helm release below, in yaml. It is shown decoded and is encoded back when saved.
+# Lines beginning with a '#' at the top of the file will be ignored,
+# and an empty file will cancel the edit.
+#
+# Computed values, the chart defaults merged with the values of the release, read-only:
+#
+#   image:
+#     tag: v1
+#   replicas: 3
+#
+replicas: 3
+`, presented)
+	assert.Equal(t, "replicas: 5\n", string(result.After))
+	assert.JSONEq(t, `{"name":"myapp","chart":{"values":{"replicas":1,"image":{"tag":"v1"}}},"config":{"replicas":5}}`, storedRelease(t, driver))
+}
@@ -0,0 +1,46 @@
+package modify
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+)
+
+// Hash algorithms comparing the release before and after the edit.
+// Any of them detects an edit; sha256 is the default since md5 and sha1 are flagged by security scanners.
+const (
+	HashMD5    = "md5"
+	HashSHA1   = "sha1"
+	HashSHA256 = "sha256"
+)
+
+// newHash returns a hash of the algorithm of the options, sha256 when empty
+func (opts Options) newHash() (hash.Hash, error) {
+	switch opts.HashAlgo {
+	case HashMD5:
+		return md5.New(), nil
+	case HashSHA1:
+		return sha1.New(), nil
+	case HashSHA256, "":
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q", opts.HashAlgo)
+	}
+}
+
+// sameSum tells whether both contents have the same checksum
+func (opts Options) sameSum(a, b []byte) (bool, error) {
+	h, err := opts.newHash()
+	if err != nil {
+		return false, err
+	}
+
+	h.Write(a)
+	sumA := h.Sum(nil)
+	h.Reset()
+	h.Write(b)
+
+	return string(sumA) == string(h.Sum(nil)), nil
+}
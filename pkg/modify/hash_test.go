@@ -0,0 +1,35 @@
+package modify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHashAlgo(t *testing.T) {
+	for _, algo := range []string{"", HashMD5, HashSHA1, HashSHA256} {
+		t.Run(algo, func(t *testing.T) {
+			opts := Options{
+				Name:      "mysecret",
+				Namespace: "mynamespace",
+				Driver:    newDriver(t, `{"name":"value"}`),
+				HashAlgo:  algo,
+			}
+
+			opts.Edit = replace("value", "value")
+			result, err := Run(context.TODO(), nil, opts)
+			require.NoError(t, err)
+			assert.False(t, result.Changed)
+
+			opts.Edit = replace("value", "updated")
+			result, err = Run(context.TODO(), nil, opts)
+			require.NoError(t, err)
+			assert.True(t, result.Changed)
+		})
+	}
+
+	_, err := Run(context.TODO(), nil, Options{Name: "mysecret", Namespace: "mynamespace", Driver: newDriver(t, `{}`), HashAlgo: "crc32"})
+	assert.EqualError(t, err, `unsupported hash algorithm "crc32"`)
+}
@@ -0,0 +1,576 @@
+package modify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/editor"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/strings/slices"
+)
+
+// DefaultReleaseKey is the key of the secret Helm stores the release in
+const DefaultReleaseKey = "release"
+
+// EditFunc lets the user edit the release written to file.
+// It may replace the secret, for instance with a version changed on the server meanwhile; the edit is then applied to it.
+type EditFunc func(file string, secret *v1.Secret) error
+
+// Options configures how a release is edited
+type Options struct {
+	// Name and Namespace of the secret holding the release
+	Name      string
+	Namespace string
+
+	// Driver reads and writes the secret, secrets are used when nil
+	Driver secrets.StorageDriver
+	// Edit opens the editor, $KUBE_EDITOR or $EDITOR is used when nil
+	Edit EditFunc
+	// EditContent edits the release in memory, without writing it to a temporary file; Edit is ignored when set
+	EditContent func(content []byte, secret *v1.Secret) ([]byte, error)
+
+	// Format of the release in the editor, yaml when empty
+	Format string
+	// SortKeys presents the keys of the release in sorted order
+	SortKeys bool
+	// ValuesOnly edits only the user supplied values of the release
+	ValuesOnly bool
+	// ChartFile edits only the named file packaged in the chart of the release
+	ChartFile string
+	// NotesOnly edits only the rendered NOTES.txt of the release, as text
+	NotesOnly bool
+	// NoGzip stores the release uncompressed
+	NoGzip bool
+	// Normalize stores the release JSON in a canonical form, with sorted keys,
+	// so identical releases are stored as identical bytes
+	Normalize bool
+	// ReleaseKey is the key of the secret holding the release, release when empty.
+	// When the secret has no such key, its only base64+gzip key is edited.
+	ReleaseKey string
+	// IncludeKeys, when not empty, and ExcludeKeys restrict the keys of the secret which may be decoded.
+	// The others are never decoded, so huge values aren't loaded, and are stored back verbatim.
+	IncludeKeys []string
+	ExcludeKeys []string
+	// Strict rejects an edit adding fields Helm doesn't know to the release, typos like info.stauts
+	// which Helm would silently drop
+	Strict bool
+	// SemanticCompare ignores an edit whose content parses to the same data as before,
+	// such as reordered keys or requoted strings
+	SemanticCompare bool
+	// TrimWhitespace ignores the trailing whitespace added by editors
+	TrimWhitespace bool
+	// HashAlgo compares the release before and after the edit, one of md5, sha1 or sha256; sha256 when empty
+	HashAlgo string
+	// RemoveOnSignal removes the temporary file and exits when interrupted
+	RemoveOnSignal bool
+	// NoComments doesn't prepend the guidance comments to the release in the editor
+	NoComments bool
+	// ShowComputed adds the values of the chart merged with the values of the release to the comments,
+	// for reference while editing
+	ShowComputed bool
+	// KeepFile keeps the temporary file holding the edited release, in plain text, for debugging
+	KeepFile bool
+
+	// FieldManager recorded on the secret
+	FieldManager string
+	// ApplyTimeout bounds the update of the secret, it starts when the editor is closed.
+	// The context given to Run only bounds fetching the secret. There is no timeout when zero.
+	ApplyTimeout time.Duration
+	// DryRun edits the release without updating the secret
+	DryRun bool
+	// NewRevision stores the edit as a new revision of the release instead of updating the secret,
+	// the edited revision is marked superseded
+	NewRevision bool
+	// ApplyUnchanged encodes the release again and updates the secret even if the release wasn't edited,
+	// to store it with other encoding options
+	ApplyUnchanged bool
+	// Verify gets the secret again after the update and checks that the server stored the edited release,
+	// which a mutating admission webhook may have changed. It isn't supported with NewRevision.
+	Verify bool
+	// PreserveFields reads the secret again before updating it, so the fields changed on the server
+	// while the release was edited, such as annotations or finalizers, are kept
+	PreserveFields bool
+	// BeforeUpdate is called with the secret about to be updated, an error aborts the update
+	BeforeUpdate func(secret *v1.Secret) error
+}
+
+// Result is the outcome of an edit
+type Result struct {
+	// Secret holding the edited release
+	Secret *v1.Secret
+	// Before and After are the release as presented in the editor and as edited
+	Before []byte
+	After  []byte
+	// Changed tells whether the release was edited
+	Changed bool
+	// EncodedBefore and EncodedAfter are the release as stored in the secret before and after the edit,
+	// EncodedAfter is only set when the release was edited
+	EncodedBefore []byte
+	EncodedAfter  []byte
+	// File is the temporary file the release was edited in, when it was kept with KeepFile
+	File string
+}
+
+// Run gets the secret holding a release, lets the user edit the release and updates the secret
+func Run(ctx context.Context, client kubernetes.Interface, opts Options) (Result, error) {
+	result := Result{}
+
+	driver := opts.Driver
+	if driver == nil {
+		driver = &secrets.SecretDriver{Client: client}
+	}
+
+	edit := opts.Edit
+	if edit == nil {
+		edit = func(file string, secret *v1.Secret) error {
+			return editor.Edit(file)
+		}
+	}
+
+	_, err := opts.newHash()
+	if err != nil {
+		return result, err
+	}
+
+	secret, err := driver.Get(ctx, opts.Name, opts.Namespace)
+	if err != nil {
+		return result, err
+	}
+	result.Secret = secret
+
+	err = secrets.CheckMutable(secret)
+	if err != nil {
+		return result, err
+	}
+
+	content, key, err := opts.releaseOf(secret)
+	if err != nil {
+		return result, err
+	}
+	result.EncodedBefore = secret.Data[key]
+	if key != opts.configuredReleaseKey() {
+		logrus.Warnf("no key %q in secret %q, editing its only base64+gzip key %q", opts.configuredReleaseKey(), secret.Name, key)
+	}
+
+	result.Before, err = opts.Render(content)
+	if err != nil {
+		return result, err
+	}
+	logrus.Debugf("rendered %d bytes of release to %d bytes for the editor", len(content), len(result.Before))
+
+	if opts.EditContent != nil {
+		result.After, err = opts.EditContent(result.Before, secret)
+	} else {
+		result.After, result.File, err = opts.editFile(edit, content, result.Before, secret)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	before := result.Before
+	if opts.TrimWhitespace {
+		before = trimWhitespace(before)
+		result.After = trimWhitespace(result.After)
+	}
+
+	same, err := opts.sameSum(before, result.After)
+	if err != nil {
+		return result, err
+	}
+	if !same && opts.SemanticCompare && opts.semanticallyEqual(before, result.After) {
+		logrus.Infof("the edit of secret %q only changed the formatting of the release, ignoring it", opts.Name)
+		same = true
+	}
+	result.Changed = !same
+	if !result.Changed {
+		if !opts.ApplyUnchanged {
+			return result, nil
+		}
+		logrus.Infof("the release of secret %q wasn't changed, forcing its update", opts.Name)
+	}
+
+	// the edit function may have replaced the secret, the edit applies to its release
+	content, key, err = opts.releaseOf(secret)
+	if err != nil {
+		return result, err
+	}
+
+	edited, err := opts.apply(content, result.After)
+	if err != nil {
+		return result, err
+	}
+
+	if opts.Normalize {
+		edited, err = release.SortKeys(edited)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	encoded, err := Encode(edited, opts.NoGzip)
+	if err != nil {
+		return result, err
+	}
+
+	err = verifyEncoding(edited, encoded)
+	if err != nil {
+		return result, err
+	}
+	logSize(edited, encoded)
+	base := secret.Data[key]
+	secret.Data[key] = encoded
+	result.EncodedAfter = encoded
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if opts.PreserveFields && !opts.NewRevision {
+		secret, err = refreshed(ctx, driver, secret, key, base)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if opts.BeforeUpdate != nil {
+		err = opts.BeforeUpdate(secret)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	applyCtx, cancel := context.Background(), func() {}
+	if opts.ApplyTimeout > 0 {
+		applyCtx, cancel = context.WithTimeout(applyCtx, opts.ApplyTimeout)
+	}
+	defer cancel()
+
+	var updated *v1.Secret
+	if opts.NewRevision {
+		updated, err = createRevision(applyCtx, driver, secret, content, edited, opts)
+	} else {
+		updated, err = driver.Update(applyCtx, secret, opts.fieldManager())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return result, fmt.Errorf("updating secret %q timed out after %s: %w", opts.Name, opts.ApplyTimeout, err)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Secret = updated
+
+	if opts.Verify && !opts.NewRevision {
+		err = opts.verifyStored(applyCtx, driver, updated, edited)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// verifyStored gets the secret again and checks that the release stored on the server is the edited one
+func (opts Options) verifyStored(ctx context.Context, driver secrets.StorageDriver, updated *v1.Secret, edited []byte) error {
+	stored, err := secrets.Fresh(driver).Get(ctx, updated.Name, updated.Namespace)
+	if err != nil {
+		return fmt.Errorf("verifying secret %q: %w", updated.Name, err)
+	}
+
+	content, _, err := opts.releaseOf(stored)
+	if err != nil {
+		return fmt.Errorf("verifying secret %q: %w", updated.Name, err)
+	}
+
+	same, err := opts.sameSum(content, edited)
+	if err != nil {
+		return err
+	}
+
+	if !same {
+		return fmt.Errorf("the release stored in secret %q differs from the edit, it was changed on the server, by a mutating admission webhook for instance", updated.Name)
+	}
+
+	return nil
+}
+
+// editFile writes the content to a temporary file, lets the user edit it and reads it back.
+// Unless NoComments is set, the release is preceded by guidance comments, stripped when read back.
+// ErrEmptyEdit is returned when the file is saved empty.
+// The temporary file is returned when it is kept.
+func (opts Options) editFile(edit EditFunc, decoded, content []byte, secret *v1.Secret) ([]byte, string, error) {
+	tempfile, err := os.CreateTemp("", fmt.Sprintf("%s-%s-*%s", opts.Namespace, opts.Name, opts.extension()))
+	if err != nil {
+		return nil, "", err
+	}
+	tempfile.Close()
+
+	kept := ""
+	if opts.KeepFile {
+		kept = tempfile.Name()
+	} else {
+		defer RemoveFile(tempfile.Name())
+		if opts.RemoveOnSignal {
+			stop := removeFileOnSignal(tempfile.Name())
+			defer stop()
+		}
+	}
+
+	if opts.commented() {
+		content = append(opts.editComments(decoded), content...)
+	}
+
+	err = os.WriteFile(tempfile.Name(), content, 0644)
+	if err != nil {
+		return nil, kept, err
+	}
+
+	err = edit(tempfile.Name(), secret)
+	if err != nil {
+		return nil, kept, err
+	}
+
+	after, err := os.ReadFile(tempfile.Name())
+	if err != nil {
+		return nil, kept, err
+	}
+
+	if opts.commented() {
+		after = stripComments(after)
+	}
+
+	// like kubectl edit, an emptied file cancels the edit rather than wiping the release
+	if len(bytes.TrimSpace(after)) == 0 {
+		return nil, kept, ErrEmptyEdit
+	}
+
+	return after, kept, nil
+}
+
+// Render converts a decoded release to the content presented in the editor
+func (opts Options) Render(content []byte) ([]byte, error) {
+	if opts.ChartFile != "" {
+		return release.ChartFile(content, opts.ChartFile)
+	}
+
+	if opts.NotesOnly {
+		return release.Notes(content)
+	}
+
+	if opts.SortKeys {
+		var err error
+		content, err = release.SortKeys(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ValuesOnly {
+		var err error
+		content, err = release.Values(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return release.ToFormat(opts.format(), content)
+}
+
+// apply applies the content of the editor to the decoded release
+func (opts Options) apply(content, after []byte) ([]byte, error) {
+	if opts.ChartFile != "" {
+		return release.SetChartFile(content, opts.ChartFile, after)
+	}
+
+	if opts.NotesOnly {
+		return release.SetNotes(content, after)
+	}
+
+	edited, err := release.FromFormat(opts.format(), after)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("parsed the edited %s, %d bytes, to %d bytes of JSON", opts.format(), len(after), len(edited))
+
+	if opts.ValuesOnly {
+		return release.SetValues(content, edited)
+	}
+
+	err = release.ValidateTimestamps(edited)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Strict {
+		err = checkUnknownFields(content, edited)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return edited, nil
+}
+
+// checkUnknownFields returns an error when the edit added fields Helm doesn't know to the release.
+// Unknown fields the release already had, from a newer Helm for instance, are accepted.
+func checkUnknownFields(content, edited []byte) error {
+	existing, err := release.UnknownFields(content)
+	if err != nil {
+		return err
+	}
+
+	unknown, err := release.UnknownFields(edited)
+	if err != nil {
+		return err
+	}
+
+	added := []string{}
+	for _, field := range unknown {
+		if !slices.Contains(existing, field) {
+			added = append(added, field)
+		}
+	}
+
+	if len(added) > 0 {
+		return fmt.Errorf("unknown fields in the edited release, which Helm would ignore: %s", strings.Join(added, ", "))
+	}
+
+	return nil
+}
+
+// IsUnchanged tells whether the content of the file is the release as presented in the editor
+func (opts Options) IsUnchanged(before, after []byte) bool {
+	if opts.TrimWhitespace {
+		return bytes.Equal(trimWhitespace(before), trimWhitespace(after))
+	}
+
+	return bytes.Equal(before, after)
+}
+
+// configuredReleaseKey returns the key of the secret the release is expected in
+func (opts Options) configuredReleaseKey() string {
+	if opts.ReleaseKey == "" {
+		return DefaultReleaseKey
+	}
+
+	return opts.ReleaseKey
+}
+
+// ReleaseKeyOf returns the key of the secret holding the release: the configured one when the secret has it,
+// or else its only key holding base64+gzip data
+func (opts Options) ReleaseKeyOf(secret *v1.Secret) (string, error) {
+	key := opts.configuredReleaseKey()
+	if _, ok := secret.Data[key]; ok {
+		if !opts.DecodesKey(key) {
+			return "", fmt.Errorf("key %q of secret %q holds the release but is excluded from decoding", key, secret.Name)
+		}
+		return key, nil
+	}
+
+	candidates := []string{}
+	for k, v := range secret.Data {
+		if opts.DecodesKey(k) && release.DetectLayers(v).Equal(release.HelmLayers) {
+			candidates = append(candidates, k)
+		}
+	}
+
+	if len(candidates) != 1 {
+		return "", fmt.Errorf("no key %q in secret %q, and no single base64+gzip key to edit instead", key, secret.Name)
+	}
+
+	return candidates[0], nil
+}
+
+// DecodesKey returns whether the key of the secret may be decoded, according to IncludeKeys and ExcludeKeys
+func (opts Options) DecodesKey(key string) bool {
+	if len(opts.IncludeKeys) > 0 && !slices.Contains(opts.IncludeKeys, key) {
+		return false
+	}
+
+	return !slices.Contains(opts.ExcludeKeys, key)
+}
+
+// releaseOf decodes the release stored in the secret and returns the key holding it
+func (opts Options) releaseOf(secret *v1.Secret) ([]byte, string, error) {
+	key, err := opts.ReleaseKeyOf(secret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	logrus.Debugf("decoding key %q of secret %q, %d bytes", key, secret.Name, len(secret.Data[key]))
+	content, err := release.Decode(secret.Data[key])
+	return content, key, release.WithKey(err, key)
+}
+
+// format returns the format of the release in the editor
+func (opts Options) format() string {
+	if opts.Format == release.FormatJSON {
+		return release.FormatJSON
+	}
+
+	return release.FormatYAML
+}
+
+// fieldManager returns the field manager recorded on the secret
+func (opts Options) fieldManager() string {
+	if opts.FieldManager == "" {
+		return secrets.DefaultFieldManager
+	}
+
+	return opts.FieldManager
+}
+
+// extension returns the extension of the file opened in the editor
+func (opts Options) extension() string {
+	if opts.ChartFile != "" {
+		return filepath.Ext(opts.ChartFile)
+	}
+
+	if opts.NotesOnly {
+		return ".txt"
+	}
+
+	return "." + opts.format()
+}
+
+// secretSizeLimit is the maximum size of the data of a secret
+const secretSizeLimit = 1024 * 1024
+
+// logSize logs at debug level the size of the release, to tell how close it is to the size limit of secrets
+func logSize(decoded, encoded []byte) {
+	logrus.Debugf("release is %d bytes decoded, %d bytes encoded (compression ratio %.2f), %.1f%% of the %d bytes secret size limit",
+		len(decoded), len(encoded), float64(len(decoded))/float64(len(encoded)), 100*float64(len(encoded))/secretSizeLimit, secretSizeLimit)
+}
+
+// verifyEncoding decodes the encoded release again, so an encoding bug aborts the edit instead of corrupting the release
+func verifyEncoding(edited, encoded []byte) error {
+	decoded, err := release.Decode(encoded)
+	if err != nil {
+		return fmt.Errorf("the edited release doesn't decode back, the secret was left unchanged: %w", err)
+	}
+
+	if !bytes.Equal(decoded, edited) {
+		return fmt.Errorf("the edited release decodes back to different content, the secret was left unchanged")
+	}
+
+	return nil
+}
+
+// Encode encodes the release the way it is stored in the secret
+func Encode(content []byte, noGzip bool) ([]byte, error) {
+	if !noGzip {
+		return release.Encode(content)
+	}
+
+	logrus.Warnf("storing the release uncompressed, it may exceed the 1MB size limit of secrets")
+	return release.EncodeUncompressed(content), nil
+}
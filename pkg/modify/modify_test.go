@@ -0,0 +1,731 @@
+package modify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// replace returns an edit function replacing old with new in the file
+func replace(old, new string) EditFunc {
+	return func(file string, secret *v1.Secret) error {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(file, []byte(strings.ReplaceAll(string(content), old, new)), 0600)
+	}
+}
+
+func newDriver(t *testing.T, content string) *secrets.FakeDriver {
+	encoded, err := release.Encode([]byte(content))
+	require.NoError(t, err)
+
+	return secrets.NewFakeDriver(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace", ResourceVersion: "1"},
+		Data:       map[string][]byte{"release": encoded},
+	})
+}
+
+func storedRelease(t *testing.T, driver secrets.StorageDriver) string {
+	secret, err := driver.Get(context.TODO(), "mysecret", "mynamespace")
+	require.NoError(t, err)
+
+	content, err := release.Decode(secret.Data["release"])
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestRun(t *testing.T) {
+	testcases := []struct {
+		name     string
+		opts     Options
+		release  string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "edit the release",
+			opts:     Options{Edit: replace("value", "updated")},
+			release:  `{"name":"value"}`,
+			expected: `{"name":"updated"}`,
+			changed:  true,
+		},
+		{
+			name:     "edit the values",
+			opts:     Options{Edit: replace("v1", "v2"), ValuesOnly: true},
+			release:  `{"config":{"tag":"v1"},"manifest":"image: app:v1"}`,
+			expected: `{"config":{"tag":"v2"},"manifest":"image: app:v1"}`,
+			changed:  true,
+		},
+		{
+			name:     "no change",
+			opts:     Options{Edit: replace("\n", "  \n"), TrimWhitespace: true},
+			release:  `{"name":"value"}`,
+			expected: `{"name":"value"}`,
+		},
+		{
+			name:     "dry run",
+			opts:     Options{Edit: replace("value", "updated"), DryRun: true},
+			release:  `{"name":"value"}`,
+			expected: `{"name":"value"}`,
+			changed:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newDriver(t, tc.release)
+			tc.opts.Name = "mysecret"
+			tc.opts.Namespace = "mynamespace"
+			tc.opts.Driver = driver
+
+			result, err := Run(context.TODO(), nil, tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.changed, result.Changed)
+			assert.JSONEq(t, tc.expected, storedRelease(t, driver))
+		})
+	}
+}
+
+func TestRunBeforeUpdate(t *testing.T) {
+	driver := newDriver(t, `{"name":"value"}`)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      replace("value", "updated"),
+		BeforeUpdate: func(secret *v1.Secret) error {
+			return fmt.Errorf("not confirmed")
+		},
+	})
+	require.EqualError(t, err, "not confirmed")
+	assert.True(t, result.Changed)
+	assert.Equal(t, "name: updated\n", string(result.After))
+	assert.JSONEq(t, `{"name":"value"}`, storedRelease(t, driver))
+}
+
+func TestRunChartFile(t *testing.T) {
+	driver := newDriver(t, `{"chart":{"files":[{"name":"config/app.conf","data":"bGlzdGVuIDgwCg=="}],"templates":[]},"name":"myapp"}`)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      replace("80", "8080"),
+		ChartFile: "config/app.conf",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "listen 80\n", string(result.Before))
+	assert.JSONEq(t, `{"chart":{"files":[{"name":"config/app.conf","data":"bGlzdGVuIDgwODAK"}],"templates":[]},"name":"myapp"}`, storedRelease(t, driver))
+}
+
+func TestRunChartFileKeepsYAMLAnchors(t *testing.T) {
+	anchored := "defaults: &defaults\n  replicas: 1\nprod:\n  <<: *defaults\n  image: app:v1\n"
+	file := fmt.Sprintf(`{"chart":{"files":[{"name":"config/envs.yaml","data":%q}]},"name":"myapp"}`, base64.StdEncoding.EncodeToString([]byte(anchored)))
+	driver := newDriver(t, file)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      func(file string, secret *v1.Secret) error { return nil },
+		ChartFile: "config/envs.yaml",
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+	assert.JSONEq(t, file, storedRelease(t, driver))
+
+	_, err = Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      replace("app:v1", "app:v2"),
+		ChartFile: "config/envs.yaml",
+	})
+	require.NoError(t, err)
+
+	edited, err := release.ChartFile([]byte(storedRelease(t, driver)), "config/envs.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(anchored, "app:v1", "app:v2", 1), string(edited))
+}
+
+// slowDriver never completes updates before the context is done, and records how long it waited
+type slowDriver struct {
+	*secrets.FakeDriver
+	waited time.Duration
+}
+
+func (d *slowDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	start := time.Now()
+	<-ctx.Done()
+	d.waited = time.Since(start)
+	return nil, ctx.Err()
+}
+
+func TestRunApplyTimeout(t *testing.T) {
+	driver := &slowDriver{FakeDriver: newDriver(t, `{"name":"value"}`)}
+	edit := replace("value", "updated")
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit: func(file string, secret *v1.Secret) error {
+			time.Sleep(50 * time.Millisecond)
+			return edit(file, secret)
+		},
+		ApplyTimeout: 20 * time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, err.Error(), `updating secret "mysecret" timed out after 20ms`)
+	assert.True(t, result.Changed)
+	// the timeout only starts once the editor is closed
+	assert.GreaterOrEqual(t, driver.waited, 20*time.Millisecond)
+}
+
+func TestRunNotesOnly(t *testing.T) {
+	driver := newDriver(t, `{"info":{"notes":"Visit http://myapp\n","status":"deployed"},"name":"myapp"}`)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      replace("http:", "https:"),
+		NotesOnly: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Visit http://myapp\n", string(result.Before))
+	assert.JSONEq(t, `{"info":{"notes":"Visit https://myapp\n","status":"deployed"},"name":"myapp"}`, storedRelease(t, driver))
+}
+
+func TestRunLogsSize(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	logrus.SetLevel(logrus.DebugLevel)
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	_, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    newDriver(t, `{"name":"value"}`),
+		Edit:      replace("value", "updated"),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, logrus.DebugLevel, hook.LastEntry().Level)
+	assert.Regexp(t, `^release is 18 bytes decoded, \d+ bytes encoded \(compression ratio \d+\.\d\d\), \d+\.\d% of the 1048576 bytes secret size limit$`, hook.LastEntry().Message)
+}
+
+func TestRunNewRevision(t *testing.T) {
+	encode := func(content string) []byte {
+		encoded, err := release.Encode([]byte(content))
+		require.NoError(t, err)
+		return encoded
+	}
+	revision := func(version int, status string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("sh.helm.release.v1.myapp.v%d", version),
+				Namespace: "mynamespace",
+				Labels:    map[string]string{"owner": "helm", "name": "myapp", "version": fmt.Sprint(version), "status": status},
+			},
+			Type: "helm.sh/release.v1",
+			Data: map[string][]byte{"release": encode(fmt.Sprintf(`{"name":"myapp","version":%d,"info":{"status":"%s"},"config":{"tag":"v%d"}}`, version, status, version))},
+		}
+	}
+	driver := secrets.NewFakeDriver(revision(1, "superseded"), revision(2, "deployed"))
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:        "sh.helm.release.v1.myapp.v2",
+		Namespace:   "mynamespace",
+		Driver:      driver,
+		Edit:        replace("v2", "v3"),
+		ValuesOnly:  true,
+		NewRevision: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sh.helm.release.v1.myapp.v3", result.Secret.Name)
+	assert.Equal(t, "3", result.Secret.Labels["version"])
+	assert.Equal(t, "deployed", result.Secret.Labels["status"])
+	assert.Equal(t, v1.SecretType("helm.sh/release.v1"), result.Secret.Type)
+
+	created, err := release.Parse(result.Secret.Data["release"])
+	require.NoError(t, err)
+	assert.Equal(t, 3, created.Version)
+	assert.Equal(t, "deployed", created.Info.Status)
+	assert.Equal(t, map[string]interface{}{"tag": "v3"}, created.Config)
+
+	previous, err := driver.Get(context.TODO(), "sh.helm.release.v1.myapp.v2", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, "superseded", previous.Labels["status"])
+	rel, err := release.Parse(previous.Data["release"])
+	require.NoError(t, err)
+	assert.Equal(t, "superseded", rel.Info.Status)
+	assert.Equal(t, map[string]interface{}{"tag": "v2"}, rel.Config)
+}
+
+func TestRunNewRevisionFromOlder(t *testing.T) {
+	revision := func(version int, status string) *v1.Secret {
+		encoded, err := release.Encode([]byte(fmt.Sprintf(`{"name":"myapp","version":%d,"info":{"status":"%s"},"config":{"tag":"v%d"}}`, version, status, version)))
+		require.NoError(t, err)
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("sh.helm.release.v1.myapp.v%d", version),
+				Namespace: "mynamespace",
+				Labels:    map[string]string{"owner": "helm", "name": "myapp", "version": fmt.Sprint(version), "status": status},
+			},
+			Type: "helm.sh/release.v1",
+			Data: map[string][]byte{"release": encoded},
+		}
+	}
+	driver := secrets.NewFakeDriver(revision(1, "superseded"), revision(2, "deployed"))
+
+	// editing revision 1 rolls the release forward from it, revision 2 is the one no longer deployed
+	result, err := Run(context.TODO(), nil, Options{
+		Name:        "sh.helm.release.v1.myapp.v1",
+		Namespace:   "mynamespace",
+		Driver:      driver,
+		Edit:        replace("v1", "v3"),
+		ValuesOnly:  true,
+		NewRevision: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sh.helm.release.v1.myapp.v3", result.Secret.Name)
+
+	items, err := driver.List(context.TODO(), "mynamespace", "owner=helm,name=myapp", "")
+	require.NoError(t, err)
+	statuses := map[string]string{}
+	for _, item := range items {
+		rel, err := release.Parse(item.Data["release"])
+		require.NoError(t, err)
+		assert.Equal(t, rel.Info.Status, item.Labels["status"], item.Name)
+		statuses[item.Name] = rel.Info.Status
+	}
+	assert.Equal(t, map[string]string{
+		"sh.helm.release.v1.myapp.v1": "superseded",
+		"sh.helm.release.v1.myapp.v2": "superseded",
+		"sh.helm.release.v1.myapp.v3": "deployed",
+	}, statuses)
+}
+
+func TestRunRejectsInvalidTimestamps(t *testing.T) {
+	driver := newDriver(t, `{"info":{"last_deployed":"2023-10-02T08:30:00Z"},"name":"myapp"}`)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      replace("2023-10-02T08:30:00Z", "2 Oct 2023"),
+	})
+	require.EqualError(t, err, `info.last_deployed "2 Oct 2023" is not a valid RFC3339 timestamp, like 2006-01-02T15:04:05Z`)
+	assert.True(t, result.Changed)
+	assert.JSONEq(t, `{"info":{"last_deployed":"2023-10-02T08:30:00Z"},"name":"myapp"}`, storedRelease(t, driver))
+}
+
+func TestRunEditContent(t *testing.T) {
+	driver := newDriver(t, `{"name":"myapp"}`)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit: func(file string, secret *v1.Secret) error {
+			return fmt.Errorf("no file should be edited")
+		},
+		EditContent: func(content []byte, secret *v1.Secret) ([]byte, error) {
+			return []byte(strings.Replace(string(content), "myapp", "renamed", 1)), nil
+		},
+		Format: release.FormatJSON,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.JSONEq(t, `{"name":"renamed"}`, storedRelease(t, driver))
+}
+
+func TestRunKeepFile(t *testing.T) {
+	failing := func(file string, secret *v1.Secret) error {
+		return fmt.Errorf("editor failed")
+	}
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    newDriver(t, `{"name":"myapp"}`),
+		Edit:      failing,
+		KeepFile:  true,
+	})
+	assert.EqualError(t, err, "editor failed")
+	require.NotEmpty(t, result.File)
+	defer os.Remove(result.File)
+	assert.FileExists(t, result.File)
+
+	result, err = Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    newDriver(t, `{"name":"myapp"}`),
+		Edit:      failing,
+	})
+	assert.EqualError(t, err, "editor failed")
+	assert.Empty(t, result.File)
+}
+
+func TestVerifyEncoding(t *testing.T) {
+	edited := []byte(`{"name":"myapp"}`)
+
+	encoded, err := Encode(edited, false)
+	require.NoError(t, err)
+	assert.NoError(t, verifyEncoding(edited, encoded))
+
+	assert.EqualError(t, verifyEncoding([]byte(`{"name":"other"}`), encoded), "the edited release decodes back to different content, the secret was left unchanged")
+	assert.ErrorIs(t, verifyEncoding(edited, []byte("not base64!")), release.ErrDecode)
+}
+
+func TestRunNormalize(t *testing.T) {
+	driver := newDriver(t, `{"name":"value","version":1,"config":{"b":1,"a":"<x>"}}`)
+
+	_, err := Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      replace("value", "updated"),
+		Normalize: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"config":{"a":"\u003cx\u003e","b":1},"name":"updated","version":1}`, storedRelease(t, driver))
+}
+
+func TestRunApplyUnchanged(t *testing.T) {
+	driver := newDriver(t, `{"name":"value","version":1,"config":{"b":1,"a":"x"}}`)
+
+	result, err := Run(context.TODO(), nil, Options{
+		Name:           "mysecret",
+		Namespace:      "mynamespace",
+		Driver:         driver,
+		Edit:           replace("unknown", "updated"),
+		Normalize:      true,
+		ApplyUnchanged: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+	require.NotNil(t, result.Secret)
+	assert.Equal(t, `{"config":{"a":"x","b":1},"name":"value","version":1}`, storedRelease(t, driver))
+}
+
+// mutatingDriver changes the release on update, like a mutating admission webhook
+type mutatingDriver struct {
+	*secrets.FakeDriver
+}
+
+func (d *mutatingDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	mutated, err := release.Encode([]byte(`{"name":"mutated"}`))
+	if err != nil {
+		return nil, err
+	}
+
+	secret = secret.DeepCopy()
+	secret.Data["release"] = mutated
+	return d.FakeDriver.Update(ctx, secret, fieldManager)
+}
+
+func TestRunVerify(t *testing.T) {
+	opts := Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Edit:      replace("value", "updated"),
+		Verify:    true,
+	}
+
+	opts.Driver = newDriver(t, `{"name":"value"}`)
+	_, err := Run(context.TODO(), nil, opts)
+	require.NoError(t, err)
+
+	opts.Driver = &mutatingDriver{FakeDriver: newDriver(t, `{"name":"value"}`)}
+	_, err = Run(context.TODO(), nil, opts)
+	assert.EqualError(t, err, `the release stored in secret "mysecret" differs from the edit, it was changed on the server, by a mutating admission webhook for instance`)
+
+	opts.Verify = false
+	opts.Driver = &mutatingDriver{FakeDriver: newDriver(t, `{"name":"value"}`)}
+	_, err = Run(context.TODO(), nil, opts)
+	assert.NoError(t, err)
+}
+
+func TestRunReleaseKey(t *testing.T) {
+	encoded, err := release.Encode([]byte(`{"name":"value"}`))
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name        string
+		releaseKey  string
+		includeKeys []string
+		excludeKeys []string
+		data        map[string][]byte
+		edited      string
+		err         string
+	}{
+		{
+			name:       "configured key",
+			releaseKey: "payload",
+			data:       map[string][]byte{"payload": encoded, "other": encoded},
+			edited:     "payload",
+		},
+		{
+			name:   "detected key",
+			data:   map[string][]byte{"payload": encoded, "token": []byte("s3cr3t")},
+			edited: "payload",
+		},
+		{
+			name: "no key",
+			data: map[string][]byte{"token": []byte("s3cr3t")},
+			err:  `no key "release" in secret "mysecret", and no single base64+gzip key to edit instead`,
+		},
+		{
+			name: "ambiguous keys",
+			data: map[string][]byte{"a": encoded, "b": encoded},
+			err:  `no key "release" in secret "mysecret", and no single base64+gzip key to edit instead`,
+		},
+		{
+			name:        "excluded key not detected",
+			excludeKeys: []string{"b"},
+			data:        map[string][]byte{"a": encoded, "b": encoded},
+			edited:      "a",
+		},
+		{
+			name:        "included key detected",
+			includeKeys: []string{"b"},
+			data:        map[string][]byte{"a": encoded, "b": encoded},
+			edited:      "b",
+		},
+		{
+			name:        "excluded release key",
+			excludeKeys: []string{"release"},
+			data:        map[string][]byte{"release": encoded},
+			err:         `key "release" of secret "mysecret" holds the release but is excluded from decoding`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := secrets.NewFakeDriver(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+				Data:       tc.data,
+			})
+
+			_, err := Run(context.TODO(), nil, Options{
+				Name:        "mysecret",
+				Namespace:   "mynamespace",
+				Driver:      driver,
+				Edit:        replace("value", "updated"),
+				ReleaseKey:  tc.releaseKey,
+				IncludeKeys: tc.includeKeys,
+				ExcludeKeys: tc.excludeKeys,
+			})
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			secret, err := driver.Get(context.TODO(), "mysecret", "mynamespace")
+			require.NoError(t, err)
+			content, err := release.Decode(secret.Data[tc.edited])
+			require.NoError(t, err)
+			assert.Equal(t, `{"name":"updated"}`, string(content))
+
+			assert.Len(t, secret.Data, len(tc.data))
+			for key, value := range tc.data {
+				if key != tc.edited {
+					assert.Equal(t, value, secret.Data[key], "key %q", key)
+				}
+			}
+		})
+	}
+}
+
+func TestRunExcludeKeysKeptVerbatim(t *testing.T) {
+	encoded, err := release.Encode([]byte(`{"name":"value"}`))
+	require.NoError(t, err)
+	big := bytes.Repeat([]byte{0x1f, 0x8b, 0x00, 0xff}, 1<<16)
+
+	driver := secrets.NewFakeDriver(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Data:       map[string][]byte{"release": encoded, "big": big},
+	})
+
+	_, err = Run(context.TODO(), nil, Options{
+		Name:        "mysecret",
+		Namespace:   "mynamespace",
+		Driver:      driver,
+		Edit:        replace("value", "updated"),
+		ExcludeKeys: []string{"big"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"updated"}`, storedRelease(t, driver))
+
+	secret, err := driver.Get(context.TODO(), "mysecret", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, big, secret.Data["big"])
+}
+
+func TestRunImmutable(t *testing.T) {
+	encoded, err := release.Encode([]byte(`{"name":"value"}`))
+	require.NoError(t, err)
+
+	immutable := true
+	driver := secrets.NewFakeDriver(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "mynamespace"},
+		Immutable:  &immutable,
+		Data:       map[string][]byte{"release": encoded},
+	})
+
+	edited := false
+	_, err = Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit: func(file string, secret *v1.Secret) error {
+			edited = true
+			return nil
+		},
+	})
+	assert.EqualError(t, err, `secret "mysecret" is immutable, the API server rejects any change of its data: delete and recreate it to edit it`)
+	assert.False(t, edited)
+}
+
+func TestRunStrict(t *testing.T) {
+	opts := Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Format:    release.FormatJSON,
+		Strict:    true,
+	}
+
+	opts.Driver = newDriver(t, `{"name":"myapp","info":{"status":"failed"}}`)
+	opts.Edit = replace(`"status"`, `"stauts"`)
+	_, err := Run(context.TODO(), nil, opts)
+	assert.EqualError(t, err, "unknown fields in the edited release, which Helm would ignore: info.stauts")
+	assert.JSONEq(t, `{"name":"myapp","info":{"status":"failed"}}`, storedRelease(t, opts.Driver))
+
+	opts.Driver = newDriver(t, `{"name":"myapp","info":{"status":"failed"},"future":true}`)
+	opts.Edit = replace("failed", "deployed")
+	_, err = Run(context.TODO(), nil, opts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","info":{"status":"deployed"},"future":true}`, storedRelease(t, opts.Driver))
+}
+
+func TestRunKeepsManifestSecrets(t *testing.T) {
+	manifest := "---\n# Source: app/templates/secret.yaml\napiVersion: v1\nkind: Secret\nmetadata:\n  name: myapp\ntype: Opaque\ndata:\n  password: \"czNjcjN0\"\n  tls.crt: LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCg==\n---\n# Source: app/templates/deployment.yaml\nkind: Deployment\n"
+	content, err := json.Marshal(map[string]interface{}{"name": "myapp", "config": map[string]interface{}{"replicas": 1}, "manifest": manifest})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		opts     Options
+		manifest string
+	}{
+		{
+			name:     "other field edited",
+			opts:     Options{Edit: replace("replicas: 1", "replicas: 2")},
+			manifest: manifest,
+		},
+		{
+			name:     "normalized and trimmed",
+			opts:     Options{Edit: replace("replicas: 1", "replicas: 2"), Normalize: true, TrimWhitespace: true},
+			manifest: manifest,
+		},
+		{
+			name:     "json",
+			opts:     Options{Edit: replace(`"replicas": 1`, `"replicas": 2`), Format: release.FormatJSON},
+			manifest: manifest,
+		},
+		{
+			name:     "secret data edited",
+			opts:     Options{Edit: replace("czNjcjN0", "bmV3cGFzcw==")},
+			manifest: strings.Replace(manifest, "czNjcjN0", "bmV3cGFzcw==", 1),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newDriver(t, string(content))
+			tc.opts.Name = "mysecret"
+			tc.opts.Namespace = "mynamespace"
+			tc.opts.Driver = driver
+
+			result, err := Run(context.TODO(), nil, tc.opts)
+			require.NoError(t, err)
+			require.True(t, result.Changed)
+
+			rel, err := release.Unmarshal([]byte(storedRelease(t, driver)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.manifest, rel.Manifest)
+		})
+	}
+}
+
+func TestRunSemanticCompare(t *testing.T) {
+	testcases := []struct {
+		name    string
+		edited  string
+		changed bool
+	}{
+		{
+			name:   "reordered keys",
+			edited: "config:\n  tag: v1\n  replicas: 2\nname: myapp\n",
+		},
+		{
+			name:   "requoted strings",
+			edited: "name: 'myapp'\nconfig:\n  replicas: 2\n  tag: \"v1\"\n",
+		},
+		{
+			name:    "retyped value",
+			edited:  "name: myapp\nconfig:\n  replicas: \"2\"\n  tag: v1\n",
+			changed: true,
+		},
+		{
+			name:    "edited value",
+			edited:  "config:\n  tag: v2\n  replicas: 2\nname: myapp\n",
+			changed: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			driver := newDriver(t, `{"name":"myapp","config":{"replicas":2,"tag":"v1"}}`)
+
+			result, err := Run(context.TODO(), nil, Options{
+				Name:      "mysecret",
+				Namespace: "mynamespace",
+				Driver:    driver,
+				Format:    release.FormatYAML,
+				EditContent: func(content []byte, secret *v1.Secret) ([]byte, error) {
+					return []byte(tc.edited), nil
+				},
+				SemanticCompare: true,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.changed, result.Changed)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package modify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// refreshed reads the secret again and returns it with the edited release key, so the update only changes
+// that key and keeps whatever else changed on the server meanwhile, other data keys included. The update is refused with a conflict when
+// the release key no longer holds base, the release the edit started from.
+func refreshed(ctx context.Context, driver secrets.StorageDriver, edited *v1.Secret, key string, base []byte) (*v1.Secret, error) {
+	live, err := secrets.Fresh(driver).Get(ctx, edited.Name, edited.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(live.Data[key], base) {
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, edited.Name, fmt.Errorf("the release was changed on the server while it was edited"))
+	}
+
+	if live.ResourceVersion != edited.ResourceVersion {
+		logrus.Infof("secret %q changed on the server while it was edited, keeping these changes", edited.Name)
+	}
+
+	live.Data[key] = edited.Data[key]
+	return live, nil
+}
@@ -0,0 +1,77 @@
+package modify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestRunPreserveFields(t *testing.T) {
+	// annotate adds an annotation, a finalizer and a data key on the server while the release is being
+	// edited, and changes the release too when asked to
+	annotate := func(driver *secrets.FakeDriver, changeRelease bool) EditFunc {
+		return func(file string, secret *v1.Secret) error {
+			live, err := driver.Get(context.TODO(), "mysecret", "mynamespace")
+			require.NoError(t, err)
+			live.Annotations = map[string]string{"backup.example.com/last": "2026-10-15"}
+			live.Finalizers = []string{"example.com/protect"}
+			live.Data["token"] = []byte("s3cr3t")
+			if changeRelease {
+				live.Data["release"], err = release.Encode([]byte(`{"name":"concurrent"}`))
+				require.NoError(t, err)
+			}
+			_, err = driver.Update(context.TODO(), live, "other-controller")
+			require.NoError(t, err)
+
+			return replace("value", "updated")(file, secret)
+		}
+	}
+
+	driver := newDriver(t, `{"name":"value"}`)
+	_, err := Run(context.TODO(), nil, Options{
+		Name:           "mysecret",
+		Namespace:      "mynamespace",
+		Driver:         driver,
+		Edit:           annotate(driver, false),
+		PreserveFields: true,
+		BeforeUpdate: func(secret *v1.Secret) error {
+			secret.Labels = map[string]string{"edited": "true"}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"updated"}`, storedRelease(t, driver))
+
+	secret, err := driver.Get(context.TODO(), "mysecret", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"backup.example.com/last": "2026-10-15"}, secret.Annotations)
+	assert.Equal(t, []string{"example.com/protect"}, secret.Finalizers)
+	assert.Equal(t, map[string]string{"edited": "true"}, secret.Labels)
+	assert.Equal(t, "s3cr3t", string(secret.Data["token"]))
+
+	driver = newDriver(t, `{"name":"value"}`)
+	_, err = Run(context.TODO(), nil, Options{
+		Name:           "mysecret",
+		Namespace:      "mynamespace",
+		Driver:         driver,
+		Edit:           annotate(driver, true),
+		PreserveFields: true,
+	})
+	assert.True(t, apierrors.IsConflict(err))
+	assert.Equal(t, `{"name":"concurrent"}`, storedRelease(t, driver))
+
+	driver = newDriver(t, `{"name":"value"}`)
+	_, err = Run(context.TODO(), nil, Options{
+		Name:      "mysecret",
+		Namespace: "mynamespace",
+		Driver:    driver,
+		Edit:      annotate(driver, false),
+	})
+	assert.True(t, apierrors.IsConflict(err))
+}
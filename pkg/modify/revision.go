@@ -0,0 +1,130 @@
+package modify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/secrets"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// createRevision stores the edited release as a new deployed revision following the latest one,
+// and marks the revisions deployed until then superseded, the way helm upgrade does
+func createRevision(ctx context.Context, driver secrets.StorageDriver, secret *v1.Secret, original, edited []byte, opts Options) (*v1.Secret, error) {
+	key, err := opts.ReleaseKeyOf(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	name := secret.Labels["name"]
+	if name == "" {
+		rel, err := release.Unmarshal(original)
+		if err != nil {
+			return nil, err
+		}
+		name = rel.Name
+	}
+
+	latest, err := secrets.Latest(ctx, driver, name, secret.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed, err := driver.List(ctx, secret.Namespace, fmt.Sprintf("owner=helm,name=%s,status=%s", name, release.StatusDeployed), "")
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := strconv.Atoi(latest.Labels["version"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid version label on %q: %v", latest.Name, err)
+	}
+	version++
+
+	now := time.Now()
+	revised, err := release.SetRevision(edited, version, release.StatusDeployed, now)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := Encode(revised, opts.NoGzip)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]string, len(secret.Labels))
+	for k, v := range secret.Labels {
+		labels[k] = v
+	}
+	labels["name"] = name
+	labels["owner"] = "helm"
+	labels["status"] = release.StatusDeployed
+	labels["version"] = strconv.Itoa(version)
+	labels["modifiedAt"] = strconv.FormatInt(now.Unix(), 10)
+
+	created, err := driver.Create(ctx, &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, version),
+			Namespace:   secret.Namespace,
+			Labels:      labels,
+			Annotations: secret.Annotations,
+		},
+		Type: secret.Type,
+		Data: map[string][]byte{key: encoded},
+	}, opts.fieldManager())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deployed {
+		var content []byte
+		revision := &deployed[i]
+		if revision.Name == secret.Name {
+			// the edited secret holds the edit by now, supersede what it was read with
+			revision, content = secret, original
+		}
+		err = opts.supersede(ctx, driver, revision, content)
+		if err != nil {
+			return created, fmt.Errorf("revision %d created as %q, but marking %q superseded failed: %w", version, created.Name, revision.Name, err)
+		}
+	}
+
+	return created, nil
+}
+
+// supersede marks the revision superseded, in its labels and in its release, given as content
+// when it is not the one stored in the revision
+func (opts Options) supersede(ctx context.Context, driver secrets.StorageDriver, revision *v1.Secret, content []byte) error {
+	key, err := opts.ReleaseKeyOf(revision)
+	if err != nil {
+		return err
+	}
+
+	if content == nil {
+		content, err = release.Decode(revision.Data[key])
+		if err != nil {
+			return release.WithKey(err, key)
+		}
+	}
+
+	superseded, err := release.SetStatus(content, release.StatusSuperseded)
+	if err != nil {
+		return err
+	}
+
+	revision.Data[key], err = Encode(superseded, opts.NoGzip)
+	if err != nil {
+		return err
+	}
+
+	if revision.Labels == nil {
+		revision.Labels = map[string]string{}
+	}
+	revision.Labels["status"] = release.StatusSuperseded
+	_, err = driver.Update(ctx, revision, opts.fieldManager())
+	return err
+}
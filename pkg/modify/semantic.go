@@ -0,0 +1,45 @@
+package modify
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/rajatjindal/kubectl-modify-secret/pkg/release"
+)
+
+// semanticallyEqual tells whether both edited contents hold the same data once parsed, whatever the
+// order of their keys, their quoting or their indentation. Chart files and notes are text, and only
+// compared byte for byte.
+func (opts Options) semanticallyEqual(before, after []byte) bool {
+	if opts.ChartFile != "" || opts.NotesOnly {
+		return false
+	}
+
+	a, err := parseEdited(opts.format(), before)
+	if err != nil {
+		return false
+	}
+
+	b, err := parseEdited(opts.format(), after)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// parseEdited parses edited content to generic data, keeping numbers as written
+func parseEdited(format string, content []byte) (interface{}, error) {
+	converted, err := release.FromFormat(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.UseNumber()
+
+	var data interface{}
+	err = decoder.Decode(&data)
+	return data, err
+}
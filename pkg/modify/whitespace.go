@@ -0,0 +1,20 @@
+package modify
+
+import (
+	"bytes"
+)
+
+// trimWhitespace strips the trailing whitespace of every line and ends the content with a single newline
+func trimWhitespace(content []byte) []byte {
+	lines := bytes.Split(bytes.TrimRight(content, " \t\r\n"), []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t\r")
+	}
+
+	trimmed := bytes.Join(lines, []byte("\n"))
+	if len(trimmed) == 0 {
+		return trimmed
+	}
+
+	return append(trimmed, '\n')
+}
@@ -0,0 +1,47 @@
+package modify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimWhitespace(t *testing.T) {
+	testcases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "trailing spaces and tabs",
+			content:  "a: 1  \nb: 2\t\n",
+			expected: "a: 1\nb: 2\n",
+		},
+		{
+			name:     "missing final newline",
+			content:  "a: 1",
+			expected: "a: 1\n",
+		},
+		{
+			name:     "extra final newlines",
+			content:  "a: 1\n\n\n",
+			expected: "a: 1\n",
+		},
+		{
+			name:     "windows line endings",
+			content:  "a: 1\r\nb: 2\r\n",
+			expected: "a: 1\nb: 2\n",
+		},
+		{
+			name:     "empty",
+			content:  " \n",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(trimWhitespace([]byte(tc.content))))
+		})
+	}
+}
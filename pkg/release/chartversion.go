@@ -0,0 +1,58 @@
+package release
+
+import (
+	"encoding/json"
+)
+
+// SetChartVersions sets the version and the app version recorded in the chart metadata of the release,
+// an empty version is left unchanged
+func SetChartVersions(release []byte, version, appVersion string) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != "" {
+		rel.Chart.Metadata.Version = version
+	}
+
+	if appVersion != "" {
+		rel.Chart.Metadata.AppVersion = appVersion
+	}
+
+	return json.Marshal(rel)
+}
+
+// SetChartSources replaces the sources recorded in the chart metadata of the release,
+// the URLs the chart was pulled from, like oci://registry.example.com/charts/app
+func SetChartSources(release []byte, sources []string) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	if rel.Chart.Metadata.Extra == nil {
+		rel.Chart.Metadata.Extra = map[string]interface{}{}
+	}
+	rel.Chart.Metadata.Extra["sources"] = sources
+
+	return json.Marshal(rel)
+}
+
+// ChartSources returns the sources recorded in the chart metadata of the release
+func ChartSources(release []byte) ([]string, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	list, _ := rel.Chart.Metadata.Extra["sources"].([]interface{})
+	sources := make([]string, 0, len(list))
+	for _, source := range list {
+		if s, ok := source.(string); ok {
+			sources = append(sources, s)
+		}
+	}
+
+	return sources, nil
+}
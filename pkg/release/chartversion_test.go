@@ -0,0 +1,39 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetChartVersions(t *testing.T) {
+	rel := []byte(`{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.0","appVersion":"2.0","apiVersion":"v2"}}}`)
+
+	updated, err := SetChartVersions(rel, "1.0.1", "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.1","appVersion":"2.0","apiVersion":"v2"}}}`, string(updated))
+
+	updated, err = SetChartVersions(rel, "1.0.1", "2.1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.1","appVersion":"2.1","apiVersion":"v2"}}}`, string(updated))
+
+	_, err = SetChartVersions([]byte("{not json"), "1.0.1", "")
+	assert.ErrorIs(t, err, ErrDecode)
+}
+
+func TestSetChartSources(t *testing.T) {
+	rel := []byte(`{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.0","sources":["oci://old.example.com/charts/app"],"annotations":{"org.opencontainers.image.source":"https://github.com/example/app"}},"lock":{"digest":"sha256:abc"}}}`)
+
+	sources, err := ChartSources(rel)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oci://old.example.com/charts/app"}, sources)
+
+	updated, err := SetChartSources(rel, []string{"oci://new.example.com/charts/app"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","chart":{"metadata":{"name":"app","version":"1.0.0","sources":["oci://new.example.com/charts/app"],"annotations":{"org.opencontainers.image.source":"https://github.com/example/app"}},"lock":{"digest":"sha256:abc"}}}`, string(updated))
+
+	sources, err = ChartSources([]byte(`{"name":"myapp"}`))
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+}
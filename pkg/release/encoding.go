@@ -0,0 +1,201 @@
+package release
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Layer is an encoding wrapped around a value inside the data of a secret
+type Layer string
+
+const (
+	// LayerBase64 is a base64 encoding on top of the encoding of the API server
+	LayerBase64 Layer = "base64"
+	// LayerGzip is a gzip compression
+	LayerGzip Layer = "gzip"
+)
+
+// Layers are the encodings wrapped around a value, outermost first.
+// A Helm release is stored as base64+gzip, a plain value has no layers.
+type Layers []Layer
+
+func (l Layers) String() string {
+	if len(l) == 0 {
+		return "plain"
+	}
+
+	names := make([]string, 0, len(l))
+	for _, layer := range l {
+		names = append(names, string(layer))
+	}
+
+	return strings.Join(names, "+")
+}
+
+// HelmLayers are the layers Helm stores a release with
+var HelmLayers = Layers{LayerBase64, LayerGzip}
+
+// Equal tells whether both layers are the same, in the same order
+func (l Layers) Equal(other Layers) bool {
+	if len(l) != len(other) {
+		return false
+	}
+
+	for i := range l {
+		if l[i] != other[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// maxLayers bounds the number of layers peeled off a value
+const maxLayers = 8
+
+// DetectLayers guesses the encodings wrapped around the value of a secret key
+func DetectLayers(data []byte) Layers {
+	_, layers, err := Unwrap(data)
+	if err != nil {
+		return nil
+	}
+
+	return layers
+}
+
+// Unwrap peels the base64 and gzip layers off a value and returns its content and the layers found.
+// Gzip data is recognized by its magic bytes. A base64 layer is only recognized when it wraps gzip data,
+// JSON or another base64 layer, so plain values which happen to be valid base64 are kept as is.
+func Unwrap(data []byte) ([]byte, Layers, error) {
+	layers := Layers{}
+	for len(layers) < maxLayers {
+		if bytes.HasPrefix(data, gzipMagic) {
+			decompressed, err := gunzip(data)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			logrus.Debugf("gzip-decompressed %d bytes to %d bytes", len(data), len(decompressed))
+			data = decompressed
+			layers = append(layers, LayerGzip)
+			continue
+		}
+
+		decoded, ok := decodeBase64Layer(data, maxLayers-len(layers))
+		if !ok {
+			break
+		}
+
+		logrus.Debugf("base64-decoded %d bytes to %d bytes", len(data), len(decoded))
+		data = decoded
+		layers = append(layers, LayerBase64)
+	}
+	logrus.Debugf("unwrapped layers %s, %d bytes of content", layers, len(data))
+
+	return data, layers, nil
+}
+
+// decodeBase64Layer decodes the value if it is base64 wrapping gzip data, JSON or another such base64 layer
+func decodeBase64Layer(data []byte, depth int) ([]byte, bool) {
+	if depth == 0 {
+		return nil, false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil || len(decoded) == 0 {
+		return nil, false
+	}
+
+	if bytes.HasPrefix(decoded, gzipMagic) || json.Valid(decoded) {
+		return decoded, true
+	}
+
+	_, ok := decodeBase64Layer(decoded, depth-1)
+	return decoded, ok
+}
+
+// Wrap encodes the content with the given layers, outermost first, so Wrap reverses Unwrap
+func Wrap(content []byte, layers Layers) ([]byte, error) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		switch layers[i] {
+		case LayerBase64:
+			encoded := EncodeUncompressed(content)
+			logrus.Debugf("base64-encoded %d bytes to %d bytes", len(content), len(encoded))
+			content = encoded
+		case LayerGzip:
+			compressed, err := compress(content)
+			if err != nil {
+				return nil, err
+			}
+			logrus.Debugf("gzip-compressed %d bytes to %d bytes", len(content), len(compressed))
+			content = compressed
+		default:
+			return nil, fmt.Errorf("unknown encoding layer %q", layers[i])
+		}
+	}
+
+	return content, nil
+}
+
+// gunzip decompresses gzip data
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, &DecodeError{Step: StepGzip, Err: fmt.Errorf("erreur lors de la création du lecteur gzip : %v", err)}
+	}
+	defer r.Close()
+
+	decompressed, err := ioutil.ReadAll(r)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, gzip.ErrChecksum) {
+		return nil, &DecodeError{Step: StepGzip, Err: &TruncatedError{Partial: decompressed, Err: err}}
+	}
+	if err != nil {
+		return nil, &DecodeError{Step: StepGzip, Err: fmt.Errorf("erreur lors de la décompression gzip : %v", err)}
+	}
+
+	return decompressed, nil
+}
+
+// TruncatedError is returned when a gzip stream is truncated or fails its checksum, it holds the content
+// decompressed before the error, which may be worth recovering
+type TruncatedError struct {
+	Partial []byte
+	Err     error
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("the gzip stream is truncated or corrupt, %d bytes were decompressed before the error: %v", len(e.Partial), e.Err)
+}
+
+func (e *TruncatedError) Unwrap() error {
+	return e.Err
+}
+
+// compress compresses data with gzip. The header carries no modification time, name or comment,
+// so identical data is always compressed to identical bytes.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	gzipWriter.Header = gzip.Header{OS: gzipWriter.OS}
+
+	_, err := gzipWriter.Write(data)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la compression gzip : %v", err)
+	}
+
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la fermeture du writer gzip : %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
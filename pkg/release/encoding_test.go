@@ -0,0 +1,189 @@
+package release
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLayers(t *testing.T) {
+	content := []byte(`{"name":"myapp"}`)
+	compressed, err := Encode(content)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		data     []byte
+		expected Layers
+	}{
+		{
+			name:     "helm release",
+			data:     compressed,
+			expected: Layers{LayerBase64, LayerGzip},
+		},
+		{
+			name:     "helm release base64 encoded twice",
+			data:     EncodeUncompressed(compressed),
+			expected: Layers{LayerBase64, LayerBase64, LayerGzip},
+		},
+		{
+			name:     "uncompressed helm release",
+			data:     EncodeUncompressed(content),
+			expected: Layers{LayerBase64},
+		},
+		{
+			name:     "plain value",
+			data:     []byte("s3cr3t!"),
+			expected: Layers{},
+		},
+		{
+			name:     "plain value which is valid base64",
+			data:     []byte("password"),
+			expected: Layers{},
+		},
+		{
+			name:     "empty value",
+			data:     []byte{},
+			expected: Layers{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DetectLayers(tc.data))
+		})
+	}
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	for _, layers := range []Layers{
+		{},
+		{LayerBase64},
+		{LayerBase64, LayerGzip},
+		{LayerBase64, LayerBase64, LayerGzip},
+		{LayerGzip, LayerBase64, LayerGzip},
+	} {
+		t.Run(layers.String(), func(t *testing.T) {
+			content := []byte(`{"name":"myapp"}`)
+			wrapped, err := Wrap(content, layers)
+			require.NoError(t, err)
+
+			unwrapped, detected, err := Unwrap(wrapped)
+			require.NoError(t, err)
+			assert.Equal(t, layers, detected)
+			assert.Equal(t, content, unwrapped)
+		})
+	}
+}
+
+func TestDecodeExtraLayers(t *testing.T) {
+	content := []byte(`{"name":"myapp"}`)
+	wrapped, err := Wrap(content, Layers{LayerBase64, LayerBase64, LayerGzip})
+	require.NoError(t, err)
+
+	decoded, err := Decode(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, content, decoded)
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "line %d of the manifest\n", i)
+	}
+	content := []byte(b.String())
+
+	compressed, err := compress(content)
+	require.NoError(t, err)
+
+	checksum := append([]byte{}, compressed...)
+	checksum[len(checksum)-8] ^= 0xff
+
+	testcases := []struct {
+		name       string
+		compressed []byte
+		err        string
+	}{
+		{
+			name:       "truncated stream",
+			compressed: compressed[:len(compressed)/2],
+			err:        "unexpected EOF",
+		},
+		{
+			name:       "checksum mismatch",
+			compressed: checksum,
+			err:        "gzip: invalid checksum",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(EncodeUncompressed(tc.compressed))
+			assert.ErrorIs(t, err, ErrDecode)
+
+			var truncated *TruncatedError
+			require.ErrorAs(t, err, &truncated)
+			assert.NotEmpty(t, truncated.Partial)
+			assert.True(t, bytes.HasPrefix(content, truncated.Partial))
+			assert.EqualError(t, err, fmt.Sprintf("failed to decode release: the gzip stream is truncated or corrupt, %d bytes were decompressed before the error: %s", len(truncated.Partial), tc.err))
+		})
+	}
+}
+
+func TestEncodeDeterministic(t *testing.T) {
+	content := []byte(`{"name":"myapp"}`)
+
+	first, err := Encode(content)
+	require.NoError(t, err)
+	second, err := Encode(content)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	compressed, err := base64.StdEncoding.DecodeString(string(first))
+	require.NoError(t, err)
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	assert.True(t, r.ModTime.IsZero())
+	assert.Empty(t, r.Name)
+	assert.Empty(t, r.Comment)
+}
+
+func TestEncodingTraces(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	logrus.SetLevel(logrus.DebugLevel)
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	encoded, err := Encode([]byte(`{"name":"myapp"}`))
+	require.NoError(t, err)
+	_, err = Parse(encoded)
+	require.NoError(t, err)
+
+	messages := []string{}
+	for _, entry := range hook.AllEntries() {
+		assert.Equal(t, logrus.DebugLevel, entry.Level)
+		messages = append(messages, entry.Message)
+	}
+
+	require.Len(t, messages, 6)
+	assert.Regexp(t, `^gzip-compressed 16 bytes to \d+ bytes$`, messages[0])
+	assert.Regexp(t, `^base64-encoded \d+ bytes to \d+ bytes$`, messages[1])
+	assert.Regexp(t, `^base64-decoded the API server encoding, \d+ bytes to \d+ bytes$`, messages[2])
+	assert.Regexp(t, `^gzip-decompressed \d+ bytes to 16 bytes$`, messages[3])
+	assert.Equal(t, "unwrapped layers gzip, 16 bytes of content", messages[4])
+	assert.Equal(t, "parsed the release JSON, 16 bytes", messages[5])
+
+	hook.Reset()
+	logrus.SetLevel(logrus.InfoLevel)
+	_, err = Parse(encoded)
+	require.NoError(t, err)
+	assert.Empty(t, hook.AllEntries())
+}
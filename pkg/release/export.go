@@ -0,0 +1,144 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// exportDateLayout is the layout of LAST DEPLOYED in the output of helm get all
+const exportDateLayout = "Mon Jan _2 15:04:05 2006"
+
+// exportSections are the multi-line sections of the output of helm get all
+var exportSections = []string{"USER-SUPPLIED VALUES", "COMPUTED VALUES", "HOOKS", "MANIFEST", "NOTES"}
+
+// ParseExport reconstructs a release from the output of helm get all.
+// Only what the export holds is set: the name, namespace, revision, status, chart metadata,
+// user supplied values, hooks, manifest and notes. Computed values are ignored, they are not stored in a release.
+func ParseExport(export []byte) ([]byte, error) {
+	fields := map[string]string{}
+	sections := map[string]*strings.Builder{}
+	var current *strings.Builder
+
+	scanner := bufio.NewScanner(bytes.NewReader(export))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(export)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if section, ok := exportSection(line); ok {
+			current = &strings.Builder{}
+			sections[section] = current
+			continue
+		}
+
+		if current != nil {
+			current.WriteString(line)
+			current.WriteString("\n")
+			continue
+		}
+
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if fields["NAME"] == "" {
+		return nil, fmt.Errorf("not a helm get all export: NAME is missing")
+	}
+
+	rel := &Release{
+		Name:      fields["NAME"],
+		Namespace: fields["NAMESPACE"],
+		Info: Info{
+			Status: fields["STATUS"],
+		},
+		Chart: Chart{
+			Metadata: Metadata{
+				Name:       fields["CHART"],
+				Version:    fields["VERSION"],
+				AppVersion: fields["APP_VERSION"],
+			},
+		},
+	}
+
+	if revision := fields["REVISION"]; revision != "" {
+		version, err := strconv.Atoi(revision)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REVISION %q: %v", revision, err)
+		}
+		rel.Version = version
+	}
+
+	if deployed := fields["LAST DEPLOYED"]; deployed != "" {
+		t, err := time.Parse(exportDateLayout, deployed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LAST DEPLOYED %q: %v", deployed, err)
+		}
+		rel.Info.LastDeployed = t.Format(time.RFC3339)
+	}
+
+	if values, ok := sections["USER-SUPPLIED VALUES"]; ok {
+		valuesJSON, err := yaml.YAMLToJSON([]byte(values.String()))
+		if err == nil {
+			err = decode(valuesJSON, &rel.Config)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid USER-SUPPLIED VALUES: %v", err)
+		}
+	}
+
+	if hooks, ok := sections["HOOKS"]; ok {
+		rel.Hooks = exportHooks(hooks.String())
+	}
+
+	if manifest, ok := sections["MANIFEST"]; ok {
+		rel.Manifest = strings.TrimRight(manifest.String(), "\n") + "\n"
+	}
+
+	if notes, ok := sections["NOTES"]; ok && strings.TrimSpace(notes.String()) != "" {
+		rel.Info.Extra = map[string]interface{}{"notes": strings.TrimRight(notes.String(), "\n")}
+	}
+
+	return json.Marshal(rel)
+}
+
+// exportSection tells whether the line starts one of the multi-line sections
+func exportSection(line string) (string, bool) {
+	for _, section := range exportSections {
+		if line == section+":" {
+			return section, true
+		}
+	}
+
+	return "", false
+}
+
+// exportHooks splits the HOOKS section into hooks, each made of its source path and manifest
+func exportHooks(section string) []map[string]interface{} {
+	hooks := []map[string]interface{}{}
+	for _, document := range strings.Split(section, "---\n") {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		hook := map[string]interface{}{}
+		source, manifest, ok := strings.Cut(document, "\n")
+		if ok && strings.HasPrefix(source, "# Source: ") {
+			hook["path"] = strings.TrimPrefix(source, "# Source: ")
+			document = manifest
+		}
+		hook["manifest"] = strings.TrimRight(document, "\n")
+		hooks = append(hooks, hook)
+	}
+
+	return hooks
+}
@@ -0,0 +1,67 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExport(t *testing.T) {
+	export := `NAME: myapp
+LAST DEPLOYED: Mon Oct  2 08:30:00 2023
+NAMESPACE: default
+STATUS: deployed
+REVISION: 3
+CHART: app
+VERSION: 1.1.0
+APP_VERSION: 2.0
+TEST SUITE: None
+USER-SUPPLIED VALUES:
+image:
+  tag: v2
+replicas: 2
+
+COMPUTED VALUES:
+image:
+  tag: v2
+replicas: 2
+service: ClusterIP
+
+HOOKS:
+---
+# Source: app/templates/migrate.yaml
+kind: Job
+metadata:
+  name: migrate
+MANIFEST:
+---
+# Source: app/templates/deployment.yaml
+kind: Deployment
+
+NOTES:
+Visit http://myapp
+`
+
+	content, err := ParseExport([]byte(export))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "myapp",
+		"namespace": "default",
+		"version": 3,
+		"info": {"status": "deployed", "last_deployed": "2023-10-02T08:30:00Z", "notes": "Visit http://myapp"},
+		"chart": {"metadata": {"name": "app", "version": "1.1.0", "appVersion": "2.0"}},
+		"config": {"image": {"tag": "v2"}, "replicas": 2},
+		"hooks": [{"path": "app/templates/migrate.yaml", "manifest": "kind: Job\nmetadata:\n  name: migrate"}],
+		"manifest": "---\n# Source: app/templates/deployment.yaml\nkind: Deployment\n"
+	}`, string(content))
+}
+
+func TestParseExportPartial(t *testing.T) {
+	content, err := ParseExport([]byte("NAME: myapp\nUSER-SUPPLIED VALUES:\nnull\nMANIFEST:\nkind: ConfigMap\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"myapp","manifest":"kind: ConfigMap\n"}`, string(content))
+
+	_, err = ParseExport([]byte("kind: ConfigMap\n"))
+	assert.EqualError(t, err, "not a helm get all export: NAME is missing")
+}
@@ -0,0 +1,92 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// unmarshalWithExtra unmarshals data into v, a pointer to a struct, and returns the fields v has no field for
+func unmarshalWithExtra(data []byte, v interface{}) (map[string]interface{}, error) {
+	err := decode(data, v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	err = decode(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range jsonFields(reflect.TypeOf(v).Elem()) {
+		delete(fields, name)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return fields, nil
+}
+
+// marshalWithExtra marshals v, a struct, along with the extra fields.
+// Nested structs left empty are omitted, like omitempty does for other types.
+func marshalWithExtra(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, isStruct := range jsonFields(reflect.TypeOf(v)) {
+		if isStruct && string(fields[name]) == "{}" {
+			delete(fields, name)
+		}
+	}
+
+	for name, value := range extra {
+		if _, known := fields[name]; known {
+			continue
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = raw
+	}
+
+	return json.Marshal(fields)
+}
+
+// jsonFields returns the JSON names of the fields of a struct type, and whether each is a struct
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type.Kind() == reflect.Struct
+	}
+
+	return fields
+}
+
+// decode unmarshals JSON keeping numbers as they are written
+func decode(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,106 @@
+package release
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// ChartFiles returns the files packaged in the chart of the release
+func ChartFiles(release []byte) ([]File, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := chartFileEntries(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(entries))
+	for _, entry := range entries {
+		file, err := chartFile(entry)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// ChartFile returns the decoded content of a file packaged in the chart of the release
+func ChartFile(release []byte, name string) ([]byte, error) {
+	files, err := ChartFiles(release)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.Name == name {
+			return file.Data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("chart file %q not found in the release", name)
+}
+
+// SetChartFile replaces the content of a file packaged in the chart of the release, leaving the rest of the chart untouched
+func SetChartFile(release []byte, name string, data []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := chartFileEntries(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry["name"] == name {
+			entry["data"] = base64.StdEncoding.EncodeToString(data)
+			return json.Marshal(rel)
+		}
+	}
+
+	return nil, fmt.Errorf("chart file %q not found in the release", name)
+}
+
+// chartFileEntries returns the entries of chart.files, as stored in the release
+func chartFileEntries(rel *Release) ([]map[string]interface{}, error) {
+	raw, ok := rel.Chart.Extra["files"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: chart.files is not a list", ErrDecode)
+	}
+
+	entries := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: chart.files holds an entry which is not an object", ErrDecode)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// chartFile decodes an entry of chart.files
+func chartFile(entry map[string]interface{}) (File, error) {
+	name, _ := entry["name"].(string)
+	encoded, _ := entry["data"].(string)
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return File{}, fmt.Errorf("%w: chart file %q: %v", ErrDecode, name, err)
+	}
+
+	return File{Name: name, Data: data}, nil
+}
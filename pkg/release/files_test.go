@@ -0,0 +1,30 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartFiles(t *testing.T) {
+	content := []byte(`{"chart":{"files":[{"name":"config/app.conf","data":"bGlzdGVuIDgwCg=="},{"name":"README.md","data":"IyBhcHAK"}],"templates":[{"name":"templates/cm.yaml","data":"a2luZDogQ29uZmlnTWFw"}]},"name":"myapp"}`)
+
+	files, err := ChartFiles(content)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "config/app.conf", files[0].Name)
+
+	data, err := ChartFile(content, "config/app.conf")
+	require.NoError(t, err)
+	assert.Equal(t, "listen 80\n", string(data))
+
+	updated, err := SetChartFile(content, "config/app.conf", []byte("listen 8080\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chart":{"files":[{"name":"config/app.conf","data":"bGlzdGVuIDgwODAK"},{"name":"README.md","data":"IyBhcHAK"}],"templates":[{"name":"templates/cm.yaml","data":"a2luZDogQ29uZmlnTWFw"}]},"name":"myapp"}`, string(updated))
+
+	_, err = ChartFile(content, "missing.txt")
+	assert.EqualError(t, err, `chart file "missing.txt" not found in the release`)
+	_, err = SetChartFile(content, "missing.txt", nil)
+	assert.Error(t, err)
+}
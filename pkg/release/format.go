@@ -0,0 +1,50 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Formats in which the release can be edited
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// ToFormat converts JSON content to the format it is edited in.
+// Strings YAML would read as another type, like "no" or "12345", are quoted so they keep their type.
+func ToFormat(format string, content []byte) ([]byte, error) {
+	if format == FormatJSON {
+		var buf bytes.Buffer
+		err := json.Indent(&buf, content, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid release: %v", ErrDecode, err)
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	return yaml.JSONToYAML(content)
+}
+
+// FromFormat converts edited content back to JSON
+func FromFormat(format string, content []byte) ([]byte, error) {
+	if format == FormatJSON {
+		var buf bytes.Buffer
+		err := json.Compact(&buf, content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	converted, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %v", err)
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,157 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	content := []byte(`{"config":{"image":{"tag":"v1"},"replicas":2},"name":"myapp"}`)
+
+	testcases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   FormatYAML,
+			expected: "config:\n  image:\n    tag: v1\n  replicas: 2\nname: myapp\n",
+		},
+		{
+			format:   FormatJSON,
+			expected: "{\n  \"config\": {\n    \"image\": {\n      \"tag\": \"v1\"\n    },\n    \"replicas\": 2\n  },\n  \"name\": \"myapp\"\n}\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			buffer, err := ToFormat(tc.format, content)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(buffer))
+
+			roundTrip, err := FromFormat(tc.format, buffer)
+			require.NoError(t, err)
+			assert.Equal(t, content, roundTrip)
+		})
+	}
+
+	_, err := FromFormat(FormatJSON, []byte(`{"name":`))
+	assert.Error(t, err)
+
+	_, err = FromFormat(FormatYAML, []byte("name: [myapp"))
+	assert.Error(t, err)
+}
+
+func TestFormatKeepsScalarTypes(t *testing.T) {
+	testcases := []struct {
+		name  string
+		value string
+	}{
+		{name: "yaml 1.1 boolean", value: `"no"`},
+		{name: "short yaml 1.1 boolean", value: `"y"`},
+		{name: "on", value: `"on"`},
+		{name: "true string", value: `"true"`},
+		{name: "numeric string", value: `"12345"`},
+		{name: "octal looking string", value: `"0777"`},
+		{name: "hexadecimal looking string", value: `"0x1F"`},
+		{name: "exponent looking string", value: `"1e3"`},
+		{name: "version string", value: `"1.10"`},
+		{name: "date string", value: `"2023-10-01"`},
+		{name: "null string", value: `"null"`},
+		{name: "tilde string", value: `"~"`},
+		{name: "empty string", value: `""`},
+		{name: "multi line string", value: `"first\nsecond\n"`},
+		{name: "boolean", value: `false`},
+		{name: "integer", value: `12345`},
+		{name: "large integer", value: `9007199254740993`},
+		{name: "float", value: `1.5`},
+		{name: "null", value: `null`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := []byte(`{"config":{"value":` + tc.value + `}}`)
+
+			buffer, err := ToFormat(FormatYAML, content)
+			require.NoError(t, err)
+
+			roundTrip, err := FromFormat(FormatYAML, buffer)
+			require.NoError(t, err)
+			assert.Equal(t, string(content), string(roundTrip))
+		})
+	}
+}
+
+func TestFormatMultiLineValues(t *testing.T) {
+	testcases := []struct {
+		name  string
+		value string
+	}{
+		{name: "without final newline", value: `"first\nsecond"`},
+		{name: "with several final newlines", value: `"first\nsecond\n\n\n"`},
+		{name: "blank lines", value: `"first\n\n\nsecond\n"`},
+		{name: "leading indentation", value: `"  indented\nnot indented\n"`},
+		{name: "trailing spaces", value: `"first  \nsecond \n"`},
+		{name: "tabs", value: `"key:\tvalue\n\tindented\n"`},
+		{name: "carriage returns", value: `"first\r\nsecond\r\n"`},
+		{name: "yaml syntax", value: `"key: value\n# not a comment\n- item\n---\n"`},
+		{name: "manifest", value: `"---\n# Source: app/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: myapp\n"`},
+		{name: "certificate", value: `"-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIU\n-----END CERTIFICATE-----\n"`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := []byte(`{"config":{"value":` + tc.value + `}}`)
+
+			buffer, err := ToFormat(FormatYAML, content)
+			require.NoError(t, err)
+
+			roundTrip, err := FromFormat(FormatYAML, buffer)
+			require.NoError(t, err)
+			assert.Equal(t, string(content), string(roundTrip))
+		})
+	}
+}
+
+func TestFormatBlockScalars(t *testing.T) {
+	testcases := []struct {
+		name     string
+		edited   string
+		expected string
+	}{
+		{
+			name:     "literal",
+			edited:   "value: |\n  first\n  second\n",
+			expected: `{"value":"first\nsecond\n"}`,
+		},
+		{
+			name:     "literal strip",
+			edited:   "value: |-\n  first\n  second\n",
+			expected: `{"value":"first\nsecond"}`,
+		},
+		{
+			name:     "literal keep",
+			edited:   "value: |+\n  first\n\n",
+			expected: `{"value":"first\n\n"}`,
+		},
+		{
+			name:     "literal indentation indicator",
+			edited:   "value: |2\n    indented\n  second\n",
+			expected: `{"value":"  indented\nsecond\n"}`,
+		},
+		{
+			name:     "folded",
+			edited:   "value: >\n  first\n  second\n\n  third\n",
+			expected: `{"value":"first second\nthird\n"}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := FromFormat(FormatYAML, []byte(tc.edited))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(converted))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReplaceImage replaces the image reference old, like myrepo/app:v1, with new in the values of the release,
+// and in its rendered manifest when inManifest is set. References are replaced wherever they appear in a string,
+// and values splitting them into repository and tag keys, as most charts do, are updated too.
+// It returns the edited release and the path of each replaced occurrence.
+func ReplaceImage(release []byte, old, new string, inManifest bool) ([]byte, []string, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := imageReplacer{old: old, new: new}
+	r.oldRepo, r.oldTag = splitImage(old)
+	r.newRepo, r.newTag = splitImage(new)
+
+	rel.Config = r.replaceMap(rel.Config, "config")
+	if inManifest {
+		var n int
+		rel.Manifest, n = r.replaceString(rel.Manifest)
+		for i := 0; i < n; i++ {
+			r.replaced = append(r.replaced, "manifest")
+		}
+	}
+
+	edited, err := json.Marshal(rel)
+	return edited, r.replaced, err
+}
+
+// splitImage splits an image reference into its repository and tag, the tag is empty when missing
+func splitImage(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, ""
+	}
+
+	return image[:i], image[i+1:]
+}
+
+// imageReplacer replaces an image reference in the values of a release
+type imageReplacer struct {
+	old, new        string
+	oldRepo, oldTag string
+	newRepo, newTag string
+	replaced        []string
+}
+
+// replaceString replaces the reference in the string and returns the number of occurrences replaced.
+// An occurrence must not be part of a longer reference, such as myrepo/app:v10, other/myrepo/app:v1
+// or myrepo/app:v1@sha256:..., pinned to a digest.
+func (r *imageReplacer) replaceString(s string) (string, int) {
+	var b strings.Builder
+	n := 0
+	for start := 0; ; {
+		i := strings.Index(s[start:], r.old)
+		if i < 0 {
+			b.WriteString(s[start:])
+			break
+		}
+		i += start
+		end := i + len(r.old)
+
+		if (i > 0 && isImageChar(s[i-1], true)) || (end < len(s) && isImageChar(s[end], false)) {
+			b.WriteString(s[start:end])
+		} else {
+			b.WriteString(s[start:i])
+			b.WriteString(r.new)
+			n++
+		}
+		start = end
+	}
+
+	return b.String(), n
+}
+
+// isImageChar tells whether the character may continue an image reference,
+// before it when leading, after it otherwise
+func isImageChar(c byte, leading bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.', c == '-':
+		return true
+	case c == '/':
+		return leading
+	case c == '@':
+		return !leading
+	}
+
+	return false
+}
+
+// replaceValue replaces the reference in the value found at the path of the values
+func (r *imageReplacer) replaceValue(value interface{}, path string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return r.replaceMap(v, path)
+	case []interface{}:
+		for i := range v {
+			v[i] = r.replaceValue(v[i], fmt.Sprintf("%s[%d]", path, i))
+		}
+		return v
+	case string:
+		replaced, n := r.replaceString(v)
+		for i := 0; i < n; i++ {
+			r.replaced = append(r.replaced, path)
+		}
+		return replaced
+	}
+
+	return value
+}
+
+// replaceMap replaces the reference in the map found at the path of the values, in key order so the
+// occurrences are reported in a stable order
+func (r *imageReplacer) replaceMap(m map[string]interface{}, path string) map[string]interface{} {
+	if r.oldTag != "" && m["repository"] == r.oldRepo && m["tag"] != nil && fmt.Sprint(m["tag"]) == r.oldTag {
+		m["repository"] = r.newRepo
+		m["tag"] = r.newTag
+		r.replaced = append(r.replaced, path)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		m[k] = r.replaceValue(m[k], path+"."+k)
+	}
+
+	return m
+}
@@ -0,0 +1,49 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaceImage(t *testing.T) {
+	content := `{"name":"myapp","config":{` +
+		`"image":{"repository":"myrepo/app","tag":"v1"},` +
+		`"sidecar":{"image":"myrepo/app:v1"},` +
+		`"initContainers":[{"image":"docker.io/myrepo/app:v1"},{"image":"myrepo/app:v10"},{"image":"myrepo/app:v1@sha256:abc"}],` +
+		`"args":"--image=myrepo/app:v1 --other=myrepo/app:v1"},` +
+		`"manifest":"image: myrepo/app:v1\nimage: \"myrepo/app:v1\"\n"}`
+
+	edited, replaced, err := ReplaceImage([]byte(content), "myrepo/app:v1", "myrepo/app:v2", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"config.args", "config.args", "config.image", "config.sidecar.image"}, replaced)
+	assert.JSONEq(t, `{"name":"myapp","config":{`+
+		`"image":{"repository":"myrepo/app","tag":"v2"},`+
+		`"sidecar":{"image":"myrepo/app:v2"},`+
+		`"initContainers":[{"image":"docker.io/myrepo/app:v1"},{"image":"myrepo/app:v10"},{"image":"myrepo/app:v1@sha256:abc"}],`+
+		`"args":"--image=myrepo/app:v2 --other=myrepo/app:v2"},`+
+		`"manifest":"image: myrepo/app:v1\nimage: \"myrepo/app:v1\"\n"}`, string(edited))
+
+	edited, replaced, err = ReplaceImage([]byte(content), "myrepo/app:v1", "registry.example.com/app:v2", true)
+	require.NoError(t, err)
+	assert.Len(t, replaced, 6)
+	rel, err := Unmarshal(edited)
+	require.NoError(t, err)
+	assert.Equal(t, "image: registry.example.com/app:v2\nimage: \"registry.example.com/app:v2\"\n", rel.Manifest)
+	assert.Equal(t, map[string]interface{}{"repository": "registry.example.com/app", "tag": "v2"}, rel.Config["image"])
+
+	_, replaced, err = ReplaceImage([]byte(content), "other/app:v1", "other/app:v2", true)
+	require.NoError(t, err)
+	assert.Empty(t, replaced)
+}
+
+func TestSplitImage(t *testing.T) {
+	repo, tag := splitImage("registry.example.com:5000/app:v1")
+	assert.Equal(t, "registry.example.com:5000/app", repo)
+	assert.Equal(t, "v1", tag)
+
+	repo, tag = splitImage("registry.example.com:5000/app")
+	assert.Equal(t, "registry.example.com:5000/app", repo)
+	assert.Empty(t, tag)
+}
@@ -0,0 +1,52 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SplitManifest splits the manifest of a release into its YAML documents, separated by --- lines,
+// and checks each of them parses. Empty documents, such as before a leading separator or holding
+// only comments, are left out.
+func SplitManifest(manifest string) ([]string, error) {
+	documents := []string{}
+	n := 0
+	for _, document := range splitDocuments(manifest) {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+		n++
+
+		parsed, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, fmt.Errorf("document %d of the manifest is invalid: %v", n, err)
+		}
+		if bytes.Equal(parsed, []byte("null")) {
+			continue
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+// splitDocuments splits the manifest on its --- lines, without the blank lines around each document
+func splitDocuments(manifest string) []string {
+	documents := []string{}
+	var current strings.Builder
+	for _, line := range strings.Split(manifest, "\n") {
+		if strings.TrimRight(line, " \t\r") == "---" {
+			documents = append(documents, strings.Trim(current.String(), "\n"))
+			current.Reset()
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+
+	return append(documents, strings.Trim(current.String(), "\n"))
+}
@@ -0,0 +1,58 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitManifest(t *testing.T) {
+	testcases := []struct {
+		name      string
+		manifest  string
+		documents []string
+		err       string
+	}{
+		{
+			name:     "helm manifest",
+			manifest: "---\n# Source: app/templates/service.yaml\nkind: Service\nmetadata:\n  name: app\n---\n# Source: app/templates/deployment.yaml\nkind: Deployment\nmetadata:\n  name: app\n",
+			documents: []string{
+				"# Source: app/templates/service.yaml\nkind: Service\nmetadata:\n  name: app",
+				"# Source: app/templates/deployment.yaml\nkind: Deployment\nmetadata:\n  name: app",
+			},
+		},
+		{
+			name:      "trailing separator",
+			manifest:  "kind: Service\n---\n",
+			documents: []string{"kind: Service"},
+		},
+		{
+			name:      "empty and comment only documents",
+			manifest:  "---\n\n---\n# Source: app/templates/disabled.yaml\n---  \nkind: ConfigMap\ndata:\n  key: |\n    ---\n    not a separator\n",
+			documents: []string{"kind: ConfigMap\ndata:\n  key: |\n    ---\n    not a separator"},
+		},
+		{
+			name:      "empty manifest",
+			manifest:  "",
+			documents: []string{},
+		},
+		{
+			name:     "invalid document",
+			manifest: "kind: Service\n---\nkind: [Deployment\n",
+			err:      "document 2 of the manifest is invalid: yaml: line 1: did not find expected ',' or ']'",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			documents, err := SplitManifest(tc.manifest)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.documents, documents)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package release
+
+import (
+	"encoding/json"
+)
+
+// Notes returns the rendered NOTES.txt of the release
+func Notes(release []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, _ := rel.Info.Extra["notes"].(string)
+	return []byte(notes), nil
+}
+
+// SetNotes replaces the rendered NOTES.txt of the release
+func SetNotes(release, notes []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	if rel.Info.Extra == nil {
+		rel.Info.Extra = map[string]interface{}{}
+	}
+	rel.Info.Extra["notes"] = string(notes)
+
+	return json.Marshal(rel)
+}
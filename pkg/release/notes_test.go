@@ -0,0 +1,28 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes(t *testing.T) {
+	content := []byte(`{"info":{"notes":"Visit http://myapp\n","status":"deployed"},"name":"myapp"}`)
+
+	notes, err := Notes(content)
+	require.NoError(t, err)
+	assert.Equal(t, "Visit http://myapp\n", string(notes))
+
+	updated, err := SetNotes(content, []byte("Visit https://myapp: {not yaml\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"info":{"notes":"Visit https://myapp: {not yaml\n","status":"deployed"},"name":"myapp"}`, string(updated))
+
+	notes, err = Notes([]byte(`{"name":"myapp"}`))
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	updated, err = SetNotes([]byte(`{"name":"myapp"}`), []byte("new notes"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"info":{"notes":"new notes"},"name":"myapp"}`, string(updated))
+}
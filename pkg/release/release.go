@@ -0,0 +1,224 @@
+package release
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDecode is returned when a stored release cannot be decoded
+var ErrDecode = errors.New("failed to decode release")
+
+// Steps of decoding a stored release, reported by DecodeError
+const (
+	StepBase64 = "base64"
+	StepGzip   = "gzip"
+	StepJSON   = "json"
+)
+
+// DecodeError is returned when a stored release cannot be decoded, it tells which step failed
+// and, when known, in which key of the secret. It matches ErrDecode with errors.Is.
+type DecodeError struct {
+	Step string
+	Key  string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("%v: failed to %s-decode key %q: %v", ErrDecode, e.Step, e.Key, e.Err)
+	}
+
+	return fmt.Sprintf("%v: %v", ErrDecode, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is tells errors.Is that a DecodeError is an ErrDecode
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// WithKey names the key of the secret in the DecodeError wrapped by err, if any, and returns err
+func WithKey(err error, key string) error {
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		decodeErr.Key = key
+	}
+
+	return err
+}
+
+// gzipMagic are the first bytes of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// Decode decodes a release the way Helm stores it: gzip compressed, then base64 encoded.
+// Like Helm, releases which are only base64 encoded are read as well. Releases wrapped in extra
+// base64 or gzip layers by mistake are unwrapped too.
+func Decode(data []byte) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, &DecodeError{Step: StepBase64, Err: fmt.Errorf("erreur lors du premier décodage base64 : %v", err)}
+	}
+	logrus.Debugf("base64-decoded the API server encoding, %d bytes to %d bytes", len(data), len(decoded))
+
+	content, _, err := Unwrap(decoded)
+	return content, err
+}
+
+// Encode encodes a release the way Helm stores it: gzip compressed, then base64 encoded
+func Encode(release []byte) ([]byte, error) {
+	return Wrap(release, HelmLayers)
+}
+
+// EncodeUncompressed encodes a release without compressing it, which Helm is able to read as well
+func EncodeUncompressed(release []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(release))
+}
+
+// SortKeys re-serializes the release with the keys of every object in sorted order
+func SortKeys(release []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(release))
+	decoder.UseNumber()
+
+	var v interface{}
+	err := decoder.Decode(&v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid release: %v", ErrDecode, err)
+	}
+
+	return json.Marshal(v)
+}
+
+// Release mirrors the fields of a Helm release the plugin works with.
+// Fields it doesn't know about are kept in Extra, so a release round-trips unchanged.
+type Release struct {
+	Name      string                   `json:"name,omitempty"`
+	Namespace string                   `json:"namespace,omitempty"`
+	Version   int                      `json:"version,omitempty"`
+	Info      Info                     `json:"info,omitempty"`
+	Chart     Chart                    `json:"chart,omitempty"`
+	Config    map[string]interface{}   `json:"config,omitempty"`
+	Manifest  string                   `json:"manifest,omitempty"`
+	Hooks     []map[string]interface{} `json:"hooks,omitempty"`
+	Extra     map[string]interface{}   `json:"-"`
+}
+
+// Status returns the status of the release, a shortcut for Info.Status in templates
+func (r *Release) Status() string {
+	return r.Info.Status
+}
+
+// Info holds the status of a Helm release
+type Info struct {
+	Status       string                 `json:"status,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	LastDeployed string                 `json:"last_deployed,omitempty"`
+	Extra        map[string]interface{} `json:"-"`
+}
+
+// Chart holds the chart a Helm release was installed from
+type Chart struct {
+	Metadata Metadata               `json:"metadata,omitempty"`
+	Values   map[string]interface{} `json:"values,omitempty"`
+	Schema   []byte                 `json:"schema,omitempty"`
+	Extra    map[string]interface{} `json:"-"`
+}
+
+// File is a file packaged in a chart
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Metadata holds the name and versions of a chart
+type Metadata struct {
+	Name       string                 `json:"name,omitempty"`
+	Version    string                 `json:"version,omitempty"`
+	AppVersion string                 `json:"appVersion,omitempty"`
+	Extra      map[string]interface{} `json:"-"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown fields in Extra
+func (r *Release) UnmarshalJSON(data []byte) error {
+	type plain Release
+	extra, err := unmarshalWithExtra(data, (*plain)(r))
+	r.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing the fields kept in Extra back
+func (r Release) MarshalJSON() ([]byte, error) {
+	type plain Release
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown fields in Extra
+func (i *Info) UnmarshalJSON(data []byte) error {
+	type plain Info
+	extra, err := unmarshalWithExtra(data, (*plain)(i))
+	i.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing the fields kept in Extra back
+func (i Info) MarshalJSON() ([]byte, error) {
+	type plain Info
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown fields in Extra
+func (c *Chart) UnmarshalJSON(data []byte) error {
+	type plain Chart
+	extra, err := unmarshalWithExtra(data, (*plain)(c))
+	c.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing the fields kept in Extra back
+func (c Chart) MarshalJSON() ([]byte, error) {
+	type plain Chart
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping unknown fields in Extra
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	type plain Metadata
+	extra, err := unmarshalWithExtra(data, (*plain)(m))
+	m.Extra = extra
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing the fields kept in Extra back
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	type plain Metadata
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+// Unmarshal parses the JSON of a decoded release
+func Unmarshal(content []byte) (*Release, error) {
+	rel := &Release{}
+	err := json.Unmarshal(content, rel)
+	if err != nil {
+		return nil, &DecodeError{Step: StepJSON, Err: fmt.Errorf("invalid release: %v", err)}
+	}
+	logrus.Debugf("parsed the release JSON, %d bytes", len(content))
+
+	return rel, nil
+}
+
+// Parse decodes a release stored in a secret and unmarshals it
+func Parse(data []byte) (*Release, error) {
+	decoded, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Unmarshal(decoded)
+}
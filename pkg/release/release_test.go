@@ -0,0 +1,171 @@
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	rel := []byte(`{"name":"myapp","version":1}`)
+
+	encoded, err := Encode(rel)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, rel, decoded)
+
+	encoded = EncodeUncompressed(rel)
+	assert.Equal(t, "eyJuYW1lIjoibXlhcHAiLCJ2ZXJzaW9uIjoxfQ==", string(encoded))
+	decoded, err = Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, rel, decoded)
+}
+
+func TestSortKeys(t *testing.T) {
+	testcases := []struct {
+		name     string
+		release  string
+		expected string
+	}{
+		{
+			name:     "top level keys",
+			release:  `{"version":1,"name":"myapp","info":{}}`,
+			expected: `{"info":{},"name":"myapp","version":1}`,
+		},
+		{
+			name:     "nested keys",
+			release:  `{"config":{"replicas":3,"image":{"tag":"v1","repository":"app"}}}`,
+			expected: `{"config":{"image":{"repository":"app","tag":"v1"},"replicas":3}}`,
+		},
+		{
+			name:     "large numbers are kept",
+			release:  `{"b":12345678901234567890,"a":1.50}`,
+			expected: `{"a":1.50,"b":12345678901234567890}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := SortKeys([]byte(tc.release))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(first))
+
+			second, err := SortKeys(first)
+			require.NoError(t, err)
+			assert.Equal(t, first, second)
+		})
+	}
+}
+
+func TestReleaseRoundTrip(t *testing.T) {
+	content := `{
+		"name": "myapp",
+		"namespace": "default",
+		"version": 3,
+		"info": {
+			"first_deployed": "2023-10-01T12:00:00Z",
+			"last_deployed": "2023-10-02T08:30:00Z",
+			"deleted": "",
+			"description": "Upgrade complete",
+			"status": "deployed",
+			"notes": "Visit http://myapp"
+		},
+		"chart": {
+			"metadata": {"name": "app", "version": "1.1.0", "appVersion": "2.0", "apiVersion": "v2", "keywords": ["web"]},
+			"lock": null,
+			"templates": [{"name": "templates/deployment.yaml", "data": "a2luZDogRGVwbG95bWVudA=="}],
+			"values": {"replicas": 1},
+			"schema": "eyJ0eXBlIjoib2JqZWN0In0=",
+			"files": []
+		},
+		"config": {"replicas": 9007199254740993, "ratio": 1.50},
+		"manifest": "---\nkind: Deployment\n",
+		"hooks": [{"name": "migrate", "weight": 5}],
+		"labels": {"team": "payments"}
+	}`
+
+	rel, err := Unmarshal([]byte(content))
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", rel.Name)
+	assert.Equal(t, 3, rel.Version)
+	assert.Equal(t, "deployed", rel.Info.Status)
+	assert.Equal(t, "Upgrade complete", rel.Info.Description)
+	assert.Equal(t, "2023-10-02T08:30:00Z", rel.Info.LastDeployed)
+	assert.Equal(t, "1.1.0", rel.Chart.Metadata.Version)
+	assert.Equal(t, []byte(`{"type":"object"}`), rel.Chart.Schema)
+	assert.Equal(t, "---\nkind: Deployment\n", rel.Manifest)
+	assert.Len(t, rel.Hooks, 1)
+	assert.Contains(t, rel.Extra, "labels")
+	assert.Contains(t, rel.Info.Extra, "notes")
+	assert.Contains(t, rel.Chart.Extra, "templates")
+	assert.Contains(t, rel.Chart.Metadata.Extra, "apiVersion")
+
+	marshaled, err := json.Marshal(rel)
+	require.NoError(t, err)
+	assert.JSONEq(t, content, string(marshaled))
+	assert.Contains(t, string(marshaled), `"replicas":9007199254740993`)
+	assert.Contains(t, string(marshaled), `"ratio":1.50`)
+
+	minimal := `{"name":"myapp"}`
+	rel, err = Unmarshal([]byte(minimal))
+	require.NoError(t, err)
+	marshaled, err = json.Marshal(rel)
+	require.NoError(t, err)
+	assert.Equal(t, minimal, string(marshaled))
+}
+
+func TestUnmarshalUnexpectedShape(t *testing.T) {
+	_, err := Unmarshal([]byte(`{"name":"myapp","version":"three"}`))
+	assert.ErrorIs(t, err, ErrDecode)
+
+	_, err = Unmarshal([]byte(`{"name":"myapp","info":{"status":["deployed"]}}`))
+	assert.ErrorIs(t, err, ErrDecode)
+}
+
+func TestDecodeErrorStep(t *testing.T) {
+	testcases := []struct {
+		name string
+		data []byte
+		step string
+	}{
+		{
+			name: "bad base64",
+			data: []byte("not base64!"),
+			step: StepBase64,
+		},
+		{
+			name: "bad gzip",
+			data: EncodeUncompressed(append([]byte{}, 0x1f, 0x8b, 0x08, 0x00)),
+			step: StepGzip,
+		},
+		{
+			name: "invalid json",
+			data: EncodeUncompressed([]byte("{not json")),
+			step: StepJSON,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.data)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrDecode))
+
+			var decodeErr *DecodeError
+			require.True(t, errors.As(err, &decodeErr))
+			assert.Equal(t, tc.step, decodeErr.Step)
+		})
+	}
+}
+
+func TestDecodeErrorKey(t *testing.T) {
+	_, err := Decode([]byte("not base64!"))
+	assert.EqualError(t, WithKey(err, "release"), `failed to decode release: failed to base64-decode key "release": erreur lors du premier décodage base64 : illegal base64 data at input byte 3`)
+
+	assert.NoError(t, WithKey(nil, "release"))
+	assert.EqualError(t, WithKey(errors.New("other"), "release"), "other")
+}
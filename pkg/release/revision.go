@@ -0,0 +1,36 @@
+package release
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Helm statuses of a release revision
+const (
+	StatusDeployed   = "deployed"
+	StatusSuperseded = "superseded"
+)
+
+// SetStatus sets the status of the release
+func SetStatus(release []byte, status string) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	rel.Info.Status = status
+	return json.Marshal(rel)
+}
+
+// SetRevision turns the release into the given revision, deployed at the given time
+func SetRevision(release []byte, version int, status string, deployed time.Time) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	rel.Version = version
+	rel.Info.Status = status
+	rel.Info.LastDeployed = deployed.UTC().Format(time.RFC3339)
+	return json.Marshal(rel)
+}
@@ -0,0 +1,45 @@
+package release
+
+import (
+	"sort"
+
+	"k8s.io/utils/strings/slices"
+)
+
+// extraFields are the fields of a Helm release the types of this package keep in Extra, by path of their object
+var extraFields = map[string][]string{
+	"":               {"labels"},
+	"info":           {"first_deployed", "deleted", "notes", "resources"},
+	"chart":          {"lock", "templates", "files"},
+	"chart.metadata": {"home", "sources", "description", "keywords", "maintainers", "icon", "apiVersion", "condition", "tags", "deprecated", "annotations", "kubeVersion", "dependencies", "type"},
+}
+
+// UnknownFields returns the paths of the fields of the release Helm doesn't know, typos like info.stauts
+// which Helm would silently drop. Values, templates and hooks are not checked.
+func UnknownFields(release []byte) ([]string, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := []string{}
+	for path, extra := range map[string]map[string]interface{}{
+		"":               rel.Extra,
+		"info":           rel.Info.Extra,
+		"chart":          rel.Chart.Extra,
+		"chart.metadata": rel.Chart.Metadata.Extra,
+	} {
+		for field := range extra {
+			if slices.Contains(extraFields[path], field) {
+				continue
+			}
+			if path != "" {
+				field = path + "." + field
+			}
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown, nil
+}
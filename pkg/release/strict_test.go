@@ -0,0 +1,27 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownFields(t *testing.T) {
+	unknown, err := UnknownFields([]byte(`{
+		"name": "myapp",
+		"labels": {"team": "payments"},
+		"info": {"status": "deployed", "notes": "", "first_deployed": ""},
+		"chart": {"metadata": {"name": "app", "apiVersion": "v2"}, "templates": [], "values": {"anything": 1}},
+		"config": {"stauts": "kept, values are free-form"}
+	}`))
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+
+	unknown, err = UnknownFields([]byte(`{"name":"myapp","nmae":"typo","info":{"stauts":"deployed"},"chart":{"metadata":{"appversion":"2.0"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chart.metadata.appversion", "info.stauts", "nmae"}, unknown)
+
+	_, err = UnknownFields([]byte("{not json"))
+	assert.ErrorIs(t, err, ErrDecode)
+}
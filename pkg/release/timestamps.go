@@ -0,0 +1,49 @@
+package release
+
+import (
+	"fmt"
+	"time"
+)
+
+// timestampFields are the timestamps of info which Helm parses as RFC3339
+var timestampFields = []string{"first_deployed", "last_deployed", "deleted"}
+
+// ValidateTimestamps ensures the timestamps of the release info are valid RFC3339, as Helm fails to read the release otherwise
+func ValidateTimestamps(release []byte) error {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range timestampFields {
+		value, ok := infoField(rel.Info, field)
+		if !ok {
+			return fmt.Errorf("info.%s must be an RFC3339 timestamp string, like 2006-01-02T15:04:05Z", field)
+		}
+
+		if value == "" {
+			continue
+		}
+
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("info.%s %q is not a valid RFC3339 timestamp, like 2006-01-02T15:04:05Z", field, value)
+		}
+	}
+
+	return nil
+}
+
+// infoField returns a string field of the release info, empty when unset; ok is false if it isn't a string
+func infoField(info Info, field string) (string, bool) {
+	if field == "last_deployed" {
+		return info.LastDeployed, true
+	}
+
+	raw, found := info.Extra[field]
+	if !found || raw == nil {
+		return "", true
+	}
+
+	value, ok := raw.(string)
+	return value, ok
+}
@@ -0,0 +1,50 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTimestamps(t *testing.T) {
+	testcases := []struct {
+		name    string
+		release string
+		err     string
+	}{
+		{
+			name:    "valid timestamps",
+			release: `{"info":{"first_deployed":"2023-10-01T12:00:00.123456789+02:00","last_deployed":"2023-10-02T08:30:00Z","deleted":""}}`,
+		},
+		{
+			name:    "no timestamps",
+			release: `{"name":"myapp"}`,
+		},
+		{
+			name:    "mangled last_deployed",
+			release: `{"info":{"last_deployed":"2023-10-02 08:30:00"}}`,
+			err:     `info.last_deployed "2023-10-02 08:30:00" is not a valid RFC3339 timestamp, like 2006-01-02T15:04:05Z`,
+		},
+		{
+			name:    "mangled first_deployed",
+			release: `{"info":{"first_deployed":"yesterday","last_deployed":"2023-10-02T08:30:00Z"}}`,
+			err:     `info.first_deployed "yesterday" is not a valid RFC3339 timestamp, like 2006-01-02T15:04:05Z`,
+		},
+		{
+			name:    "first_deployed is not a string",
+			release: `{"info":{"first_deployed":1696235400}}`,
+			err:     `info.first_deployed must be an RFC3339 timestamp string, like 2006-01-02T15:04:05Z`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTimestamps([]byte(tc.release))
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
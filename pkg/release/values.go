@@ -0,0 +1,168 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+// Values returns the user supplied values of the release as JSON
+func Values(release []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	if rel.Config == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(rel.Config)
+}
+
+// DefaultValues returns the default values of the chart of the release as JSON
+func DefaultValues(release []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	if rel.Chart.Values == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(rel.Chart.Values)
+}
+
+// EffectiveValues returns the user supplied values of the release merged over the chart defaults as JSON
+func EffectiveValues(release []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(coalesce(rel.Chart.Values, rel.Config))
+}
+
+// SetValues replaces the user supplied values of the release with the given YAML or JSON values.
+// The values are validated against the JSON schema of the chart when it has one.
+func SetValues(release, values []byte) ([]byte, error) {
+	rel, err := Unmarshal(release)
+	if err != nil {
+		return nil, err
+	}
+
+	valuesJSON, err := yaml.YAMLToJSON(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values: %v", err)
+	}
+
+	var config map[string]interface{}
+	err = decode(valuesJSON, &config)
+	if err != nil {
+		return nil, fmt.Errorf("values must be a map: %v", err)
+	}
+
+	err = ValidateValues(rel.Chart.Schema, coalesce(rel.Chart.Values, config))
+	if err != nil {
+		return nil, err
+	}
+
+	rel.Config = config
+	return json.Marshal(rel)
+}
+
+// MergeValues deep merges the YAML or JSON overlay into the values, given as JSON, and returns them as JSON.
+// Maps are merged key by key, any other value of the overlay, arrays included, replaces the existing one.
+func MergeValues(values, overlay []byte) ([]byte, error) {
+	var base map[string]interface{}
+	err := decode(values, &base)
+	if err != nil {
+		return nil, fmt.Errorf("values must be a map: %v", err)
+	}
+
+	overlayJSON, err := yaml.YAMLToJSON(overlay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values: %v", err)
+	}
+
+	var src map[string]interface{}
+	err = decode(overlayJSON, &src)
+	if err != nil {
+		return nil, fmt.Errorf("values must be a map: %v", err)
+	}
+
+	return json.Marshal(merge(base, src))
+}
+
+// merge merges src into dst, recursing into the maps both have at the same key
+func merge(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+
+	for k, v := range src {
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		srcMap, srcIsMap := v.(map[string]interface{})
+		if dstIsMap && srcIsMap {
+			dst[k] = merge(dstMap, srcMap)
+			continue
+		}
+
+		dst[k] = v
+	}
+
+	return dst
+}
+
+// ValidateValues validates the values against the JSON schema of a chart, if any
+func ValidateValues(schema []byte, values map[string]interface{}) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return fmt.Errorf("invalid chart schema: %v", err)
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	violations := []string{}
+	for _, e := range result.Errors() {
+		violations = append(violations, fmt.Sprintf("- %s: %s", e.Field(), e.Description()))
+	}
+
+	return fmt.Errorf("values don't match the chart schema:\n%s", strings.Join(violations, "\n"))
+}
+
+// coalesce merges the user supplied values over the chart defaults the way Helm does
+func coalesce(defaults, values map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+
+	for k, v := range values {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+
+		dst, dstIsMap := merged[k].(map[string]interface{})
+		src, srcIsMap := v.(map[string]interface{})
+		if dstIsMap && srcIsMap {
+			merged[k] = coalesce(dst, src)
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	return merged
+}
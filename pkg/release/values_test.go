@@ -0,0 +1,113 @@
+package release
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValues(t *testing.T) {
+	values, err := Values([]byte(`{"name":"myapp","config":{"image":{"tag":"v1"},"replicas":2}}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"image":{"tag":"v1"},"replicas":2}`, string(values))
+
+	values, err = Values([]byte(`{"name":"myapp"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(values))
+}
+
+func TestSetValues(t *testing.T) {
+	schema := base64.StdEncoding.EncodeToString([]byte(`{
+		"type": "object",
+		"required": ["image"],
+		"properties": {
+			"replicas": {"type": "integer", "minimum": 1},
+			"image": {
+				"type": "object",
+				"properties": {"tag": {"type": "string"}}
+			}
+		}
+	}`))
+	rel := `{"name":"myapp","version":1,"chart":{"values":{"image":{"tag":"latest"}},"schema":"` + schema + `"},"config":{"replicas":2}}`
+
+	testcases := []struct {
+		name     string
+		values   string
+		expected string
+		err      []string
+	}{
+		{
+			name:     "valid values",
+			values:   "replicas: 3\nimage:\n  tag: v2\n",
+			expected: `{"chart":{"schema":"` + schema + `","values":{"image":{"tag":"latest"}}},"config":{"image":{"tag":"v2"},"replicas":3},"name":"myapp","version":1}`,
+		},
+		{
+			name:   "schema violations are reported with their path",
+			values: "replicas: 0\nimage:\n  tag: 2\n",
+			err:    []string{"- replicas: Must be greater than or equal to 1", "- image.tag: Invalid type. Expected: string, given: integer"},
+		},
+		{
+			name:   "required values are looked up in chart defaults",
+			values: "replicas: 1\nimage: null\n",
+			err:    []string{"- (root): image is required"},
+		},
+		{
+			name:   "values must be a map",
+			values: "- replicas\n",
+			err:    []string{"values must be a map"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			updated, err := SetValues([]byte(rel), []byte(tc.values))
+			if len(tc.err) > 0 {
+				require.Error(t, err)
+				for _, e := range tc.err {
+					assert.Contains(t, err.Error(), e)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(updated))
+		})
+	}
+}
+
+func TestDefaultAndEffectiveValues(t *testing.T) {
+	rel := []byte(`{"name":"myapp","chart":{"values":{"image":{"repository":"app","tag":"latest"},"replicas":1,"debug":false}},"config":{"image":{"tag":"v2"},"debug":null}}`)
+
+	defaults, err := DefaultValues(rel)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"image":{"repository":"app","tag":"latest"},"replicas":1,"debug":false}`, string(defaults))
+
+	effective, err := EffectiveValues(rel)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"image":{"repository":"app","tag":"v2"},"replicas":1}`, string(effective))
+
+	defaults, err = DefaultValues([]byte(`{"name":"myapp"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(defaults))
+
+	effective, err = EffectiveValues([]byte(`{"name":"myapp"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(effective))
+}
+
+func TestMergeValues(t *testing.T) {
+	values := []byte(`{"image":{"repository":"app","tag":"v1"},"hosts":["a","b"],"replicas":2}`)
+
+	merged, err := MergeValues(values, []byte("image:\n  tag: v2\nhosts:\n- c\nresources:\n  cpu: 1\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"image":{"repository":"app","tag":"v2"},"hosts":["c"],"replicas":2,"resources":{"cpu":1}}`, string(merged))
+
+	merged, err = MergeValues([]byte(`{}`), []byte(`{"replicas":3}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"replicas":3}`, string(merged))
+
+	_, err = MergeValues(values, []byte("- replicas\n"))
+	assert.ErrorContains(t, err, "values must be a map")
+}
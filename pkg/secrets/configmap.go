@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMapDriver stores releases in configmaps, like Helm does with HELM_DRIVER=configmap
+type ConfigMapDriver struct {
+	Client kubernetes.Interface
+	// ListFromCache serves lists from the watch cache of the API server, like informers do
+	ListFromCache bool
+}
+
+// Get gets the configmap from Kubernetes
+func (d *ConfigMapDriver) Get(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	configMap, err := d.Client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromConfigMap(configMap), nil
+}
+
+// Create creates the configmap in Kubernetes
+func (d *ConfigMapDriver) Create(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	created, err := d.Client.CoreV1().ConfigMaps(secret.Namespace).Create(ctx, toConfigMap(secret), metav1.CreateOptions{FieldManager: fieldManager})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromConfigMap(created), nil
+}
+
+// Update updates the configmap to Kubernetes.
+// On conflict, the returned error lists the field managers currently owning the configmap.
+func (d *ConfigMapDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	updated, err := d.Client.CoreV1().ConfigMaps(secret.Namespace).Update(ctx, toConfigMap(secret), metav1.UpdateOptions{FieldManager: fieldManager})
+	if apierrors.IsConflict(err) {
+		return nil, conflictError(ctx, d, "configmap", secret, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromConfigMap(updated), nil
+}
+
+// List lists the configmaps matching the label and field selectors
+func (d *ConfigMapDriver) List(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Secret, error) {
+	list, err := d.Client.CoreV1().ConfigMaps(namespace).List(ctx, listOptions(labelSelector, fieldSelector, d.ListFromCache))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]v1.Secret, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, *fromConfigMap(&list.Items[i]))
+	}
+
+	return items, nil
+}
+
+// Delete deletes the configmap from Kubernetes
+func (d *ConfigMapDriver) Delete(ctx context.Context, name, namespace string) error {
+	return d.Client.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// PatchMetadata sets labels and annotations on the configmap with a merge patch
+func (d *ConfigMapDriver) PatchMetadata(ctx context.Context, name, namespace string, labels, annotations map[string]string, fieldManager string) (*v1.Secret, error) {
+	patch, err := metadataPatch(labels, annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := d.Client.CoreV1().ConfigMaps(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromConfigMap(patched), nil
+}
+
+// fromConfigMap converts a configmap to the secret form drivers hand over
+func fromConfigMap(configMap *v1.ConfigMap) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: configMap.ObjectMeta,
+		Immutable:  configMap.Immutable,
+		Data:       make(map[string][]byte, len(configMap.Data)),
+	}
+	for k, v := range configMap.Data {
+		secret.Data[k] = []byte(v)
+	}
+
+	return secret
+}
+
+// toConfigMap converts the secret form back to a configmap
+func toConfigMap(secret *v1.Secret) *v1.ConfigMap {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: secret.ObjectMeta,
+		Immutable:  secret.Immutable,
+		Data:       make(map[string]string, len(secret.Data)),
+	}
+	for k, v := range secret.Data {
+		configMap.Data[k] = string(v)
+	}
+
+	return configMap
+}
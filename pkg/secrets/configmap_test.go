@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestConfigMapDriver(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.myapp.v1",
+			Namespace: "mynamespace",
+			Labels:    map[string]string{"owner": "helm", "name": "myapp", "version": "1"},
+		},
+		Data: map[string]string{"release": "H4sIAAAA"},
+	})
+
+	driver, err := NewDriver(StorageConfigMap, client)
+	require.NoError(t, err)
+
+	latest, err := Latest(context.TODO(), driver, "myapp", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, "sh.helm.release.v1.myapp.v1", latest.Name)
+	assert.Equal(t, []byte("H4sIAAAA"), latest.Data["release"])
+
+	latest.Data["release"] = []byte("updated")
+	_, err = driver.Update(context.TODO(), latest, DefaultFieldManager)
+	require.NoError(t, err)
+
+	configMap, err := client.CoreV1().ConfigMaps("mynamespace").Get(context.TODO(), latest.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "updated", configMap.Data["release"])
+
+	patched, err := driver.PatchMetadata(context.TODO(), latest.Name, "mynamespace", map[string]string{"status": "deployed"}, map[string]string{"note": "fixed"}, DefaultFieldManager)
+	require.NoError(t, err)
+	assert.Equal(t, "deployed", patched.Labels["status"])
+	assert.Equal(t, "helm", patched.Labels["owner"])
+	assert.Equal(t, map[string]string{"note": "fixed"}, patched.Annotations)
+	assert.Equal(t, []byte("updated"), patched.Data["release"])
+
+	require.NoError(t, driver.Delete(context.TODO(), latest.Name, "mynamespace"))
+	_, err = driver.Get(context.TODO(), latest.Name, "mynamespace")
+	require.Error(t, err)
+}
+
+func TestNewDriverUnsupportedStorage(t *testing.T) {
+	_, err := NewDriver("sql", fake.NewSimpleClientset())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported storage "sql"`)
+}
+
+func TestConfigMapImmutable(t *testing.T) {
+	immutable := true
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.myapp.v1", Namespace: "mynamespace"},
+		Immutable:  &immutable,
+		Data:       map[string]string{"release": "H4sIAAAA"},
+	})
+
+	driver, err := NewDriver(StorageConfigMap, client)
+	require.NoError(t, err)
+
+	secret, err := driver.Get(context.TODO(), "sh.helm.release.v1.myapp.v1", "mynamespace")
+	require.NoError(t, err)
+	assert.EqualError(t, CheckMutable(secret), `secret "sh.helm.release.v1.myapp.v1" is immutable, the API server rejects any change of its data: delete and recreate it to edit it`)
+
+	secret.Immutable = nil
+	assert.NoError(t, CheckMutable(secret))
+}
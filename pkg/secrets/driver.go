@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// StorageSecret stores releases in secrets, the default storage of Helm
+	StorageSecret = "secret"
+	// StorageConfigMap stores releases in configmaps
+	StorageConfigMap = "configmap"
+)
+
+// StorageDriver reads and writes the objects Helm stores releases in.
+// Whatever the backend, an object is handed over as a secret holding the release in Data["release"].
+type StorageDriver interface {
+	Get(ctx context.Context, name, namespace string) (*v1.Secret, error)
+	Create(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error)
+	Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error)
+	// List lists the objects matching the label selector and, when not empty, the field selector filtered by the API server
+	List(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Secret, error)
+	Delete(ctx context.Context, name, namespace string) error
+	// PatchMetadata sets labels and annotations without touching the data, so it doesn't conflict with concurrent updates
+	PatchMetadata(ctx context.Context, name, namespace string, labels, annotations map[string]string, fieldManager string) (*v1.Secret, error)
+}
+
+// metadataPatch returns the merge patch setting the labels and annotations
+func metadataPatch(labels, annotations map[string]string) ([]byte, error) {
+	metadata := map[string]map[string]string{}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
+
+// listOptions returns the options of a list. From the cache, the API server answers from its watch cache
+// instead of reading etcd, which is cheaper with many objects but may return slightly stale data.
+func listOptions(labelSelector, fieldSelector string, fromCache bool) metav1.ListOptions {
+	opts := metav1.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector}
+	if fromCache {
+		opts.ResourceVersion = "0"
+	}
+
+	return opts
+}
+
+// CheckMutable returns an error when the secret is immutable, as the API server rejects any change of its data
+func CheckMutable(secret *v1.Secret) error {
+	if secret.Immutable != nil && *secret.Immutable {
+		return fmt.Errorf("secret %q is immutable, the API server rejects any change of its data: delete and recreate it to edit it", secret.Name)
+	}
+
+	return nil
+}
+
+// NewDriver returns the driver for the given storage, named like Helm's HELM_DRIVER
+func NewDriver(storage string, kubeclient kubernetes.Interface) (StorageDriver, error) {
+	switch storage {
+	case "", StorageSecret:
+		return &SecretDriver{Client: kubeclient}, nil
+	case StorageConfigMap:
+		return &ConfigMapDriver{Client: kubeclient}, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage %q, must be %q or %q", storage, StorageSecret, StorageConfigMap)
+	}
+}
@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FakeDriver keeps releases in memory, for tests
+type FakeDriver struct {
+	mu      sync.Mutex
+	objects map[string]*v1.Secret
+}
+
+var fakeResource = schema.GroupResource{Resource: "secrets"}
+
+// NewFakeDriver returns a fake driver holding copies of the given objects
+func NewFakeDriver(objects ...*v1.Secret) *FakeDriver {
+	d := &FakeDriver{objects: map[string]*v1.Secret{}}
+	for _, object := range objects {
+		d.objects[fakeKey(object.Name, object.Namespace)] = object.DeepCopy()
+	}
+
+	return d
+}
+
+// Get gets a copy of the object
+func (d *FakeDriver) Get(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	object, ok := d.objects[fakeKey(name, namespace)]
+	if !ok {
+		return nil, apierrors.NewNotFound(fakeResource, name)
+	}
+
+	return object.DeepCopy(), nil
+}
+
+// Create stores a copy of the object
+func (d *FakeDriver) Create(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := fakeKey(secret.Name, secret.Namespace)
+	if _, ok := d.objects[key]; ok {
+		return nil, apierrors.NewAlreadyExists(fakeResource, secret.Name)
+	}
+
+	created := secret.DeepCopy()
+	created.ResourceVersion = "1"
+	d.objects[key] = created
+
+	return created.DeepCopy(), nil
+}
+
+// Update replaces the object, failing with a conflict if its resource version is outdated
+func (d *FakeDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := fakeKey(secret.Name, secret.Namespace)
+	current, ok := d.objects[key]
+	if !ok {
+		return nil, apierrors.NewNotFound(fakeResource, secret.Name)
+	}
+	if secret.ResourceVersion != "" && secret.ResourceVersion != current.ResourceVersion {
+		return nil, apierrors.NewConflict(fakeResource, secret.Name, nil)
+	}
+
+	version, _ := strconv.Atoi(current.ResourceVersion)
+	updated := secret.DeepCopy()
+	updated.ResourceVersion = strconv.Itoa(version + 1)
+	d.objects[key] = updated
+
+	return updated.DeepCopy(), nil
+}
+
+// List lists copies of the objects matching the label selector, sorted by name
+func (d *FakeDriver) List(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Secret, error) {
+	parsedLabels, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedFields, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	items := []v1.Secret{}
+	for _, object := range d.objects {
+		if namespace != "" && object.Namespace != namespace {
+			continue
+		}
+		objectFields := fields.Set{"metadata.name": object.Name, "metadata.namespace": object.Namespace, "type": string(object.Type)}
+		if parsedLabels.Matches(labels.Set(object.Labels)) && parsedFields.Matches(objectFields) {
+			items = append(items, *object.DeepCopy())
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return fakeKey(items[i].Name, items[i].Namespace) < fakeKey(items[j].Name, items[j].Namespace)
+	})
+
+	return items, nil
+}
+
+// Delete deletes the object
+func (d *FakeDriver) Delete(ctx context.Context, name, namespace string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := fakeKey(name, namespace)
+	if _, ok := d.objects[key]; !ok {
+		return apierrors.NewNotFound(fakeResource, name)
+	}
+	delete(d.objects, key)
+
+	return nil
+}
+
+// PatchMetadata sets labels and annotations on the object, whatever its resource version
+func (d *FakeDriver) PatchMetadata(ctx context.Context, name, namespace string, labels, annotations map[string]string, fieldManager string) (*v1.Secret, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	object, ok := d.objects[fakeKey(name, namespace)]
+	if !ok {
+		return nil, apierrors.NewNotFound(fakeResource, name)
+	}
+
+	if len(labels) > 0 && object.Labels == nil {
+		object.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		object.Labels[k] = v
+	}
+
+	if len(annotations) > 0 && object.Annotations == nil {
+		object.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		object.Annotations[k] = v
+	}
+
+	version, _ := strconv.Atoi(object.ResourceVersion)
+	object.ResourceVersion = strconv.Itoa(version + 1)
+
+	return object.DeepCopy(), nil
+}
+
+func fakeKey(name, namespace string) string {
+	return namespace + "/" + name
+}
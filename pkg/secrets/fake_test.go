@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFakeDriver(t *testing.T) {
+	driver := NewFakeDriver(
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "v1", Namespace: "ns", ResourceVersion: "1", Labels: map[string]string{"owner": "helm", "name": "myapp", "version": "1"}}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "v2", Namespace: "ns", ResourceVersion: "1", Labels: map[string]string{"owner": "helm", "name": "myapp", "version": "2"}}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"}},
+	)
+
+	latest, err := Latest(context.TODO(), driver, "myapp", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", latest.Name)
+
+	updated, err := driver.Update(context.TODO(), latest, DefaultFieldManager)
+	require.NoError(t, err)
+	assert.Equal(t, "2", updated.ResourceVersion)
+
+	_, err = driver.Update(context.TODO(), latest, DefaultFieldManager)
+	assert.True(t, apierrors.IsConflict(err))
+
+	require.NoError(t, driver.Delete(context.TODO(), "v2", "ns"))
+	items, err := driver.List(context.TODO(), "ns", "owner=helm", "")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "v1", items[0].Name)
+
+	items, err = driver.List(context.TODO(), "ns", "", "metadata.name=other")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "other", items[0].Name)
+}
@@ -0,0 +1,166 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// FileDriver works offline on the manifest of a secret or configmap saved in a file,
+// as written by kubectl get -o yaml, without any call to a cluster
+type FileDriver struct {
+	// Path of the manifest
+	Path string
+	// OutPath is the file the updated manifest is written to, Path when empty
+	OutPath string
+	// Out receives the updated manifest instead of a file when set
+	Out io.Writer
+}
+
+// Read reads the manifest, configmaps are returned in the secret form of the drivers
+func (d *FileDriver) Read() (*v1.Secret, error) {
+	data, err := os.ReadFile(d.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var typeMeta metav1.TypeMeta
+	err = yaml.Unmarshal(data, &typeMeta)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", d.Path, err)
+	}
+
+	switch typeMeta.Kind {
+	case "Secret":
+		secret := &v1.Secret{}
+		err = yaml.Unmarshal(data, secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %v", d.Path, err)
+		}
+		return secret, nil
+	case "ConfigMap":
+		configMap := &v1.ConfigMap{}
+		err = yaml.Unmarshal(data, configMap)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %v", d.Path, err)
+		}
+		secret := fromConfigMap(configMap)
+		secret.Kind = "ConfigMap"
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("manifest %s holds a %q, not a Secret or a ConfigMap", d.Path, typeMeta.Kind)
+	}
+}
+
+// write writes the manifest, in the kind it was read in
+func (d *FileDriver) write(secret *v1.Secret) error {
+	var object interface{} = secret
+	if secret.Kind == "ConfigMap" {
+		configMap := toConfigMap(secret)
+		configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+		object = configMap
+	} else {
+		secret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	}
+
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	if d.Out != nil {
+		_, err = d.Out.Write(data)
+		return err
+	}
+
+	if d.OutPath != "" {
+		return os.WriteFile(d.OutPath, data, 0600)
+	}
+
+	return os.WriteFile(d.Path, data, 0600)
+}
+
+// Get reads the manifest, which must be the named object
+func (d *FileDriver) Get(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	secret, err := d.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Name != name || (namespace != "" && secret.Namespace != "" && secret.Namespace != namespace) {
+		return nil, apierrors.NewNotFound(fakeResource, name)
+	}
+
+	return secret, nil
+}
+
+// Create isn't supported, the file holds a single object
+func (d *FileDriver) Create(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	return nil, fmt.Errorf("creating %q is not supported with a local file", secret.Name)
+}
+
+// Update writes the object to the manifest
+func (d *FileDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	updated := secret.DeepCopy()
+	err := d.write(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// List returns the object of the manifest when it matches the label selector, the field selector is ignored
+func (d *FileDriver) List(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Secret, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := d.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if !selector.Matches(labels.Set(secret.Labels)) {
+		return []v1.Secret{}, nil
+	}
+
+	return []v1.Secret{*secret}, nil
+}
+
+// Delete isn't supported, the file holds a single object
+func (d *FileDriver) Delete(ctx context.Context, name, namespace string) error {
+	return fmt.Errorf("deleting %q is not supported with a local file", name)
+}
+
+// PatchMetadata sets labels and annotations on the object of the manifest
+func (d *FileDriver) PatchMetadata(ctx context.Context, name, namespace string, labels, annotations map[string]string, fieldManager string) (*v1.Secret, error) {
+	secret, err := d.Get(ctx, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(labels) > 0 && secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		secret.Labels[k] = v
+	}
+
+	if len(annotations) > 0 && secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		secret.Annotations[k] = v
+	}
+
+	return d.Update(ctx, secret, fieldManager)
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestFileDriver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: sh.helm.release.v1.myapp.v1
+  namespace: mynamespace
+  labels:
+    owner: helm
+data:
+  release: SDRzSUFBQUE=
+type: helm.sh/release.v1
+`), 0600))
+
+	driver := &FileDriver{Path: path}
+	secret, err := driver.Get(context.TODO(), "sh.helm.release.v1.myapp.v1", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("H4sIAAAA"), secret.Data["release"])
+
+	_, err = driver.Get(context.TODO(), "other", "mynamespace")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	items, err := driver.List(context.TODO(), "mynamespace", "owner=helm", "")
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	secret.Data["release"] = []byte("updated")
+	_, err = driver.Update(context.TODO(), secret, DefaultFieldManager)
+	require.NoError(t, err)
+	secret, err = driver.Get(context.TODO(), "sh.helm.release.v1.myapp.v1", "mynamespace")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), secret.Data["release"])
+	assert.Equal(t, "helm", secret.Labels["owner"])
+
+	_, err = driver.Create(context.TODO(), secret, DefaultFieldManager)
+	assert.EqualError(t, err, `creating "sh.helm.release.v1.myapp.v1" is not supported with a local file`)
+}
+
+func TestFileDriverConfigMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configmap.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: sh.helm.release.v1.myapp.v1
+data:
+  release: H4sIAAAA
+`), 0600))
+
+	out := &bytes.Buffer{}
+	driver := &FileDriver{Path: path, Out: out}
+	secret, err := driver.Get(context.TODO(), "sh.helm.release.v1.myapp.v1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("H4sIAAAA"), secret.Data["release"])
+
+	secret.Data["release"] = []byte("updated")
+	_, err = driver.Update(context.TODO(), secret, DefaultFieldManager)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+data:
+  release: updated
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  name: sh.helm.release.v1.myapp.v1
+`, out.String())
+
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\nkind: Pod\n"), 0600))
+	_, err = driver.Read()
+	assert.EqualError(t, err, `manifest `+path+` holds a "Pod", not a Secret or a ConfigMap`)
+}
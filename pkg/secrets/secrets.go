@@ -2,18 +2,135 @@ package secrets
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
+// DefaultFieldManager is the field manager recorded on the secret when none is given
+const DefaultFieldManager = "kubectl-modify-secret"
+
+// SecretDriver stores releases in secrets
+type SecretDriver struct {
+	Client kubernetes.Interface
+	// ListFromCache serves lists from the watch cache of the API server, like informers do
+	ListFromCache bool
+}
+
 // Get gets the secret from Kubernetes
-func Get(ctx context.Context, kubeclient kubernetes.Interface, name, namespace string) (*v1.Secret, error) {
-	return kubeclient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+func (d *SecretDriver) Get(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	return d.Client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// Create creates the secret in Kubernetes
+func (d *SecretDriver) Create(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	return d.Client.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{FieldManager: fieldManager})
+}
+
+// Update updates the secret to Kubernetes.
+// On conflict, the returned error lists the field managers currently owning the secret.
+func (d *SecretDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	updated, err := d.Client.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{FieldManager: fieldManager})
+	if apierrors.IsConflict(err) {
+		return nil, conflictError(ctx, d, "secret", secret, err)
+	}
+
+	return updated, err
+}
+
+// List lists the secrets matching the label and field selectors
+func (d *SecretDriver) List(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Secret, error) {
+	list, err := d.Client.CoreV1().Secrets(namespace).List(ctx, listOptions(labelSelector, fieldSelector, d.ListFromCache))
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+// Delete deletes the secret from Kubernetes
+func (d *SecretDriver) Delete(ctx context.Context, name, namespace string) error {
+	return d.Client.CoreV1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// PatchMetadata sets labels and annotations on the secret with a merge patch
+func (d *SecretDriver) PatchMetadata(ctx context.Context, name, namespace string, labels, annotations map[string]string, fieldManager string) (*v1.Secret, error) {
+	patch, err := metadataPatch(labels, annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Client.CoreV1().Secrets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+}
+
+// conflictError wraps a conflict error with the managers found on the live object
+func conflictError(ctx context.Context, driver StorageDriver, kind string, secret *v1.Secret, err error) error {
+	live, getErr := driver.Get(ctx, secret.Name, secret.Namespace)
+	if getErr != nil {
+		return err
+	}
+
+	managers := fieldManagers(live.ManagedFields)
+	if len(managers) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%s %q was modified on the server, currently managed by %s: %w", kind, secret.Name, strings.Join(managers, ", "), err)
+}
+
+// fieldManagers returns a description of each distinct manager in managedFields
+func fieldManagers(entries []metav1.ManagedFieldsEntry) []string {
+	seen := map[string]bool{}
+	managers := []string{}
+	for _, entry := range entries {
+		desc := fmt.Sprintf("%s (%s", entry.Manager, entry.Operation)
+		if entry.Time != nil {
+			desc += fmt.Sprintf(" at %s", entry.Time.UTC().Format(time.RFC3339))
+		}
+		desc += ")"
+
+		if seen[desc] {
+			continue
+		}
+		seen[desc] = true
+		managers = append(managers, desc)
+	}
+
+	return managers
 }
 
-// Update updates the secret to Kubernetes
-func Update(ctx context.Context, kubeclient kubernetes.Interface, secret *v1.Secret) (*v1.Secret, error) {
-	return kubeclient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+// Latest gets the object holding the latest revision of a Helm release
+func Latest(ctx context.Context, driver StorageDriver, releaseName, namespace string) (*v1.Secret, error) {
+	items, err := driver.List(ctx, namespace, fmt.Sprintf("owner=helm,name=%s", releaseName), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *v1.Secret
+	latestVersion := -1
+	for i := range items {
+		version, err := strconv.Atoi(items[i].Labels["version"])
+		if err != nil {
+			continue
+		}
+
+		if version > latestVersion {
+			latest = &items[i]
+			latestVersion = version
+		}
+	}
+
+	if latest == nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "helm.sh", Resource: "releases"}, releaseName)
+	}
+
+	return latest, nil
 }
@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestUpdateConflict(t *testing.T) {
+	const (
+		name      = "mysecret"
+		namespace = "mynamespace"
+	)
+
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "helm", Operation: metav1.ManagedFieldsOperationUpdate},
+				{Manager: "argocd-controller", Operation: metav1.ManagedFieldsOperationApply},
+				{Manager: "helm", Operation: metav1.ManagedFieldsOperationUpdate},
+			},
+		},
+	})
+	client.PrependReactor("update", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, name, nil)
+	})
+
+	driver := &SecretDriver{Client: client}
+	secret, err := driver.Get(context.TODO(), name, namespace)
+	require.NoError(t, err)
+
+	_, err = driver.Update(context.TODO(), secret, DefaultFieldManager)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsConflict(err))
+	assert.Contains(t, err.Error(), "currently managed by helm (Update), argocd-controller (Apply)")
+}
+
+func TestListOptions(t *testing.T) {
+	opts := listOptions("owner=helm", "type=helm.sh/release.v1", false)
+	assert.Equal(t, metav1.ListOptions{LabelSelector: "owner=helm", FieldSelector: "type=helm.sh/release.v1"}, opts)
+
+	opts = listOptions("owner=helm", "", true)
+	assert.Equal(t, metav1.ListOptions{LabelSelector: "owner=helm", ResourceVersion: "0"}, opts)
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// SplitDriver reads releases with one driver and writes them with another,
+// for clusters routing reads to API server replicas
+type SplitDriver struct {
+	Read  StorageDriver
+	Write StorageDriver
+}
+
+// Fresh returns the driver reading the objects as last written, the write driver of a SplitDriver and
+// the driver itself otherwise, for reads checking the outcome of a write a replica may not have caught up with
+func Fresh(driver StorageDriver) StorageDriver {
+	if split, ok := driver.(*SplitDriver); ok {
+		return split.Write
+	}
+	return driver
+}
+
+// Get gets the object from the read driver
+func (d *SplitDriver) Get(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	return d.Read.Get(ctx, name, namespace)
+}
+
+// Create creates the object with the write driver
+func (d *SplitDriver) Create(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	return d.Write.Create(ctx, secret, fieldManager)
+}
+
+// Update updates the object with the write driver
+func (d *SplitDriver) Update(ctx context.Context, secret *v1.Secret, fieldManager string) (*v1.Secret, error) {
+	return d.Write.Update(ctx, secret, fieldManager)
+}
+
+// List lists the objects from the read driver
+func (d *SplitDriver) List(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Secret, error) {
+	return d.Read.List(ctx, namespace, labelSelector, fieldSelector)
+}
+
+// Delete deletes the object with the write driver
+func (d *SplitDriver) Delete(ctx context.Context, name, namespace string) error {
+	return d.Write.Delete(ctx, name, namespace)
+}
+
+// PatchMetadata patches the labels and annotations of the object with the write driver
+func (d *SplitDriver) PatchMetadata(ctx context.Context, name, namespace string, labels, annotations map[string]string, fieldManager string) (*v1.Secret, error) {
+	return d.Write.PatchMetadata(ctx, name, namespace, labels, annotations, fieldManager)
+}
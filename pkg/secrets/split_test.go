@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSplitDriver(t *testing.T) {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: "ns", ResourceVersion: "1"}, Data: map[string][]byte{"key": []byte("old")}}
+	read := NewFakeDriver(secret)
+	write := NewFakeDriver(secret)
+	driver := &SplitDriver{Read: read, Write: write}
+
+	got, err := driver.Get(context.TODO(), "mysecret", "ns")
+	require.NoError(t, err)
+	got.Data["key"] = []byte("new")
+	_, err = driver.Update(context.TODO(), got, DefaultFieldManager)
+	require.NoError(t, err)
+
+	written, err := write.Get(context.TODO(), "mysecret", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(written.Data["key"]))
+
+	// the replica catches up on its own, the driver never writes to it
+	replica, err := read.Get(context.TODO(), "mysecret", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(replica.Data["key"]))
+
+	fresh, err := Fresh(driver).Get(context.TODO(), "mysecret", "ns")
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(fresh.Data["key"]))
+	assert.Equal(t, read, Fresh(read))
+
+	require.NoError(t, driver.Delete(context.TODO(), "mysecret", "ns"))
+	items, err := driver.List(context.TODO(), "ns", "", "")
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	items, err = write.List(context.TODO(), "ns", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}